@@ -0,0 +1,194 @@
+package prospety
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+)
+
+// RateLimitStatus is the quota last reported by the server. Fields the
+// server did not report are zero.
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+
+	// PausedUntil is set after a 429 until the server allows requests again.
+	PausedUntil time.Time
+
+	// Updated is when the server last reported rate limit headers. It is
+	// zero when no headers have been seen.
+	Updated time.Time
+}
+
+const (
+	// _slowdownFraction is the share of the quota below which requests are
+	// spread evenly over the time left until the reset.
+	_slowdownFraction = 0.2
+
+	_defaultRetryAfter = time.Second
+	_maxRetryAfter     = 5 * time.Minute
+)
+
+// adaptiveLimiter throttles requests based on the rate limit headers of
+// previous responses, on top of the configured ratelimit.Limiter.
+type adaptiveLimiter struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	status RateLimitStatus
+	next   time.Time
+}
+
+func newAdaptiveLimiter(c clock.Clock) *adaptiveLimiter {
+	return &adaptiveLimiter{
+		clock: c,
+	}
+}
+
+// wait blocks until the next request may be sent.
+func (a *adaptiveLimiter) wait(ctx context.Context) error {
+	d := a.reserve()
+	if d <= 0 {
+		return nil
+	}
+
+	t := a.clock.Timer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *adaptiveLimiter) reserve() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.clock.Now()
+	s := &a.status
+
+	at := now
+	if s.PausedUntil.After(at) {
+		at = s.PausedUntil
+	}
+
+	if !s.Updated.IsZero() && s.Reset.After(at) {
+		switch {
+		case s.Remaining <= 0:
+			at = s.Reset
+		case s.Limit > 0 && float64(s.Remaining) < float64(s.Limit)*_slowdownFraction:
+			interval := s.Reset.Sub(now) / time.Duration(s.Remaining)
+			if next := a.next.Add(interval); next.After(at) {
+				at = next
+			}
+		}
+
+		// Count the request against the quota until the server tells us
+		// otherwise, so concurrent callers don't all see the same budget.
+		if s.Remaining > 0 {
+			s.Remaining--
+		}
+	}
+
+	a.next = at
+	return at.Sub(now)
+}
+
+// update records the rate limit headers of resp and, on a 429, pauses all
+// requests until the server allows them again. attempt is used to back off
+// when the server does not say how long to wait.
+func (a *adaptiveLimiter) update(resp *http.Response, attempt int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.clock.Now()
+	s := &a.status
+	h := resp.Header
+
+	var reported bool
+	if v, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		reported = true
+		s.Remaining = v
+		s.Updated = now
+
+		if v, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+			s.Limit = v
+		}
+
+		if reset, ok := parseReset(h.Get("X-RateLimit-Reset"), now); ok {
+			s.Reset = reset
+		}
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	until, ok := parseRetryAfter(h.Get("Retry-After"), now)
+	if !ok && reported && s.Reset.After(now) {
+		until, ok = s.Reset, true
+	}
+	if !ok {
+		until = now.Add(_defaultRetryAfter << (attempt - 1))
+	}
+	if until.Sub(now) > _maxRetryAfter {
+		until = now.Add(_maxRetryAfter)
+	}
+
+	if until.After(s.PausedUntil) {
+		s.PausedUntil = until
+	}
+}
+
+// parseReset accepts both a unix timestamp and a number of seconds until the
+// reset, which is what the header means depending on the server.
+func parseReset(v string, now time.Time) (time.Time, bool) {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+
+	if n > 1e9 {
+		return time.Unix(n, 0), true
+	}
+
+	return now.Add(time.Duration(n) * time.Second), true
+}
+
+// parseRetryAfter accepts both forms allowed by RFC 9110: seconds and an
+// HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return now.Add(time.Duration(n) * time.Second), true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+func (a *adaptiveLimiter) snapshot() RateLimitStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.status
+}
+
+// RateLimitStatus returns the quota most recently reported by the server.
+func (c *Client) RateLimitStatus() RateLimitStatus {
+	return c.options.adaptive.snapshot()
+}
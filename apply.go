@@ -0,0 +1,389 @@
+package prospety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SearchSpec is the desired state of a single search.
+type SearchSpec struct {
+	// Label is a stable identity for the search that survives renames. It is
+	// stored in the search title behind a reserved "[prospety:label] "
+	// prefix, which also marks the search as managed by Apply. Specs
+	// without a label are matched by their exact title.
+	Label string
+	Title string
+	Limit int
+	Data  StandardSearch
+}
+
+// _managedPrefix starts the titles of searches managed by Apply. It is
+// unlikely enough to be typed by hand that searches carrying it are
+// matched by label alone.
+const _managedPrefix = "[prospety:"
+
+func (s *SearchSpec) key() string {
+	if s.Label != "" {
+		return "label:" + s.Label
+	}
+
+	return "title:" + s.Title
+}
+
+func (s *SearchSpec) remoteTitle() string {
+	if s.Label != "" {
+		return _managedPrefix + s.Label + "] " + s.Title
+	}
+
+	return s.Title
+}
+
+func (s *SearchSpec) validate() error {
+	if s.Label != "" && strings.ContainsAny(s.Label, "[]") {
+		return fmt.Errorf("search label %q must not contain brackets", s.Label)
+	}
+
+	if s.Label == "" && strings.HasPrefix(s.Title, _managedPrefix) {
+		return fmt.Errorf("search title %q uses the reserved prefix %q; set Label instead", s.Title, _managedPrefix)
+	}
+
+	return nil
+}
+
+// searchKey returns the key of a remote search and whether it carries the
+// managed prefix. Other searches are keyed by their exact title.
+func searchKey(s *Search) (string, bool) {
+	rest, ok := strings.CutPrefix(s.Title, _managedPrefix)
+	if !ok {
+		return "title:" + s.Title, false
+	}
+
+	label, _, ok := strings.Cut(rest, "] ")
+	if !ok || label == "" {
+		return "title:" + s.Title, false
+	}
+
+	return "label:" + label, true
+}
+
+type PlanAction string
+
+const (
+	PlanCreate = PlanAction("create")
+	PlanUpdate = PlanAction("update")
+	PlanDelete = PlanAction("delete")
+)
+
+type PlanChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+type PlanStep struct {
+	Action  PlanAction
+	Spec    *SearchSpec // nil for deletes
+	Current *Search     // nil for creates
+	Changes []PlanChange
+}
+
+func (s *PlanStep) title() string {
+	if s.Spec != nil {
+		return s.Spec.remoteTitle()
+	}
+
+	return s.Current.Title
+}
+
+type Plan struct {
+	Steps []PlanStep
+}
+
+func (p *Plan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// String renders the plan as a human readable diff.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+
+	var b strings.Builder
+	var create, update, del int
+	for _, s := range p.Steps {
+		switch s.Action {
+		case PlanCreate:
+			create++
+			fmt.Fprintf(&b, "+ create %q\n", s.title())
+		case PlanUpdate:
+			update++
+			fmt.Fprintf(&b, "~ update %q (id %d)\n", s.title(), s.Current.ID)
+		case PlanDelete:
+			del++
+			fmt.Fprintf(&b, "- delete %q (id %d)\n", s.title(), s.Current.ID)
+		}
+
+		for _, c := range s.Changes {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", c.Field, c.From, c.To)
+		}
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete.\n", create, update, del)
+
+	return b.String()
+}
+
+type ApplyOptions struct {
+	// DryRun only computes the plan.
+	DryRun bool
+
+	// Prune deletes the searches that are not in the desired state, with or
+	// without the managed prefix. Without it, nothing is deleted.
+	Prune bool
+
+	// Namespace limits pruning to managed searches whose label starts
+	// with it, e.g. "team-a/", so that applying one set of searches leaves
+	// the searches of other sets alone. Searches without the managed
+	// prefix are then never pruned.
+	Namespace string
+}
+
+// prunable reports whether s may be deleted when it is not desired.
+func (o *ApplyOptions) prunable(s *Search) bool {
+	if !o.Prune {
+		return false
+	}
+
+	if o.Namespace == "" {
+		return true
+	}
+
+	key, managed := searchKey(s)
+	return managed && strings.HasPrefix(strings.TrimPrefix(key, "label:"), o.Namespace)
+}
+
+// PlanSearches compares desired against the searches in the account.
+func (c *Client) PlanSearches(desired []SearchSpec, opts ApplyOptions) (*Plan, error) {
+	seen := make(map[string]bool, len(desired))
+	for i := range desired {
+		err := desired[i].validate()
+		if err != nil {
+			return nil, err
+		}
+
+		k := desired[i].key()
+		if seen[k] {
+			return nil, fmt.Errorf("duplicate search spec %q", k)
+		}
+		seen[k] = true
+	}
+
+	current, err := c.GetSearches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan searches: %w", err)
+	}
+
+	byKey := make(map[string]*Search, len(current))
+	var unmatched []*Search
+	for i := range current {
+		s := &current[i]
+		k, _ := searchKey(s)
+		if _, dup := byKey[k]; dup || !seen[k] {
+			unmatched = append(unmatched, s)
+			continue
+		}
+		byKey[k] = s
+	}
+
+	plan := &Plan{}
+	for i := range desired {
+		spec := &desired[i]
+		cur, ok := byKey[spec.key()]
+		if !ok {
+			plan.Steps = append(plan.Steps, PlanStep{
+				Action:  PlanCreate,
+				Spec:    spec,
+				Changes: diffSearch(nil, spec),
+			})
+			continue
+		}
+
+		changes := diffSearch(cur, spec)
+		if len(changes) == 0 {
+			continue
+		}
+
+		plan.Steps = append(plan.Steps, PlanStep{
+			Action:  PlanUpdate,
+			Spec:    spec,
+			Current: cur,
+			Changes: changes,
+		})
+	}
+
+	for _, s := range unmatched {
+		if !opts.prunable(s) {
+			continue
+		}
+
+		plan.Steps = append(plan.Steps, PlanStep{
+			Action:  PlanDelete,
+			Current: s,
+		})
+	}
+
+	return plan, nil
+}
+
+// Apply creates, updates and deletes searches until the account matches
+// desired. The returned plan describes what was (or, on error, was meant to
+// be) done.
+func (c *Client) Apply(ctx context.Context, desired []SearchSpec, opts ApplyOptions) (*Plan, error) {
+	c = c.WithContext(ctx)
+
+	plan, err := c.PlanSearches(desired, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	for _, s := range plan.Steps {
+		err := ctx.Err()
+		if err != nil {
+			return plan, fmt.Errorf("apply interrupted: %w", err)
+		}
+
+		switch s.Action {
+		case PlanCreate:
+			err = c.CreateSearch(s.Spec.remoteTitle(), s.Spec.Limit, s.Spec.Data)
+		case PlanUpdate:
+			err = c.UpdateSearch(s.Current.ID, s.Spec.remoteTitle(), s.Spec.Limit, s.Spec.Data)
+		case PlanDelete:
+			err = c.DeleteSearch(s.Current.ID)
+		}
+		if err != nil {
+			return plan, fmt.Errorf("failed to %s search %q: %w", s.Action, s.title(), err)
+		}
+	}
+
+	return plan, nil
+}
+
+func diffSearch(cur *Search, spec *SearchSpec) []PlanChange {
+	var changes []PlanChange
+
+	var curTitle string
+	var curLimit int
+	var curData map[string]any
+	if cur != nil {
+		curTitle = cur.Title
+		curLimit = cur.Limit
+		curData = flattenData(cur.Data)
+	}
+
+	if curTitle != spec.remoteTitle() {
+		changes = append(changes, PlanChange{
+			Field: "title",
+			From:  quoteOrNone(curTitle),
+			To:    quoteOrNone(spec.remoteTitle()),
+		})
+	}
+
+	if curLimit != spec.Limit {
+		changes = append(changes, PlanChange{
+			Field: "limit",
+			From:  fmt.Sprint(curLimit),
+			To:    fmt.Sprint(spec.Limit),
+		})
+	}
+
+	wantData := flattenData(spec.Data)
+	keys := make(map[string]bool)
+	for k := range curData {
+		keys[k] = true
+	}
+	for k := range wantData {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		from, to := curData[k], wantData[k]
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+
+		changes = append(changes, PlanChange{
+			Field: "data." + k,
+			From:  renderValue(from),
+			To:    renderValue(to),
+		})
+	}
+
+	return changes
+}
+
+// flattenData turns search data into a map keyed by JSON field name,
+// dropping unset values so that nil and empty lists compare equal.
+func flattenData(data StandardSearch) map[string]any {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+
+	m := make(map[string]any)
+	err = json.Unmarshal(raw, &m)
+	if err != nil {
+		return nil
+	}
+
+	for k, v := range m {
+		switch v := v.(type) {
+		case nil:
+			delete(m, k)
+		case string:
+			if v == "" {
+				delete(m, k)
+			}
+		case []any:
+			if len(v) == 0 {
+				delete(m, k)
+			}
+		}
+	}
+
+	return m
+}
+
+func renderValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
+
+func quoteOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+
+	return fmt.Sprintf("%q", s)
+}
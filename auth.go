@@ -0,0 +1,181 @@
+package prospety
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource provides the API key for every request, so keys can be
+// rotated without recreating the Client.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// RefreshableTokenSource is a TokenSource that can be told that its current
+// key was rejected. After a 401 Unauthorized the Client calls Refresh and
+// retries the request once with the new key.
+type RefreshableTokenSource interface {
+	TokenSource
+	Refresh(ctx context.Context) (string, error)
+}
+
+// WithTokenSource replaces the API key passed to New.
+func WithTokenSource(ts TokenSource) Option {
+	return func(option *options) error {
+		option.tokens = ts
+		return nil
+	}
+}
+
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// EnvToken reads the key from an environment variable on every request.
+type EnvToken string
+
+func (t EnvToken) Token(context.Context) (string, error) {
+	key := os.Getenv(string(t))
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s is not set", string(t))
+	}
+
+	return key, nil
+}
+
+// FileToken reads the key from a file and reloads it whenever the file's
+// modification time changes, e.g. when a mounted secret is rotated.
+type FileToken struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	key     string
+}
+
+var _ RefreshableTokenSource = (*FileToken)(nil)
+
+func NewFileToken(path string) *FileToken {
+	return &FileToken{
+		path: path,
+	}
+}
+
+func (t *FileToken) Token(context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat token file: %w", err)
+	}
+
+	if t.key != "" && info.ModTime().Equal(t.modTime) {
+		return t.key, nil
+	}
+
+	return t.load(info.ModTime())
+}
+
+func (t *FileToken) Refresh(context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat token file: %w", err)
+	}
+
+	return t.load(info.ModTime())
+}
+
+func (t *FileToken) load(modTime time.Time) (string, error) {
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("token file %s is empty", t.path)
+	}
+
+	t.key = key
+	t.modTime = modTime
+
+	return key, nil
+}
+
+// CallbackToken asks fn for the key, e.g. from a secret manager, and reuses
+// the answer for ttl. A ttl of 0 caches the key until it is rejected.
+type CallbackToken struct {
+	fn  func(ctx context.Context) (string, error)
+	ttl time.Duration
+
+	mu      sync.Mutex
+	key     string
+	fetched time.Time
+}
+
+var _ RefreshableTokenSource = (*CallbackToken)(nil)
+
+func NewCallbackToken(fn func(ctx context.Context) (string, error), ttl time.Duration) *CallbackToken {
+	return &CallbackToken{
+		fn:  fn,
+		ttl: ttl,
+	}
+}
+
+func (t *CallbackToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.key != "" && (t.ttl == 0 || time.Since(t.fetched) < t.ttl) {
+		return t.key, nil
+	}
+
+	return t.fetch(ctx)
+}
+
+func (t *CallbackToken) Refresh(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.fetch(ctx)
+}
+
+func (t *CallbackToken) fetch(ctx context.Context) (string, error) {
+	key, err := t.fn(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch token: %w", err)
+	}
+
+	t.key = key
+	t.fetched = time.Now()
+
+	return key, nil
+}
+
+// refreshToken returns a new key after rejected was refused by the API, and
+// false when there is no new key to retry with.
+func (c *Client) refreshToken(ctx context.Context, rejected string) (string, bool) {
+	var key string
+	var err error
+	if r, ok := c.options.tokens.(RefreshableTokenSource); ok {
+		key, err = r.Refresh(ctx)
+	} else {
+		key, err = c.options.tokens.Token(ctx)
+	}
+
+	if err != nil || key == rejected {
+		return "", false
+	}
+
+	return key, true
+}
@@ -0,0 +1,324 @@
+package prospety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultBulkConcurrency  = 4
+	_defaultBulkPollInterval = 10 * time.Second
+	_defaultBulkTimeout      = 10 * time.Minute
+)
+
+// ErrQuickSearchTimeout is the error of a BulkQuickSearchResult whose quick
+// search did not finish in time.
+var ErrQuickSearchTimeout = errors.New("quick search did not finish in time")
+
+type BulkQuickSearchOptions struct {
+	// Channel is the platform of every URL. Zero detects it per URL.
+	Channel ChannelType
+
+	// Concurrency is the number of quick searches submitted at once. The
+	// default is 4. Requests are still subject to the client's rate limits.
+	Concurrency int
+
+	// PollInterval is how often unfinished quick searches are checked.
+	// The default is 10s.
+	PollInterval time.Duration
+
+	// Timeout is how long to wait for the quick searches to finish after
+	// the last one was submitted. The default is 10m.
+	Timeout time.Duration
+
+	// Reuse returns finished quick searches for the same URL that are at
+	// most this old instead of creating new ones, like
+	// WithQuickSearchReuse.
+	Reuse time.Duration
+}
+
+// BulkQuickSearchResult is the outcome of the quick search for one URL.
+type BulkQuickSearchResult struct {
+	URL string
+
+	// QuickSearchID is 0 when the quick search was not created, or in
+	// dry-run mode.
+	QuickSearchID int
+	Status        SearchStatus
+	Prospect      *ProspectPreview
+
+	// Err is set when the URL was rejected, the creation failed, or the
+	// quick search did not finish in time (ErrQuickSearchTimeout).
+	Err error
+}
+
+// BulkQuickSearch creates a quick search for every URL and waits until all
+// of them have finished. It returns one result per URL, in the order of
+// urls. Failures of single URLs are reported in their result; the error is
+// only set when ctx ended before all results were collected.
+//
+// When the API does not return the created quick search, it is looked up
+// while polling among the quick searches that did not exist before, by the
+// channel of the prospect it found. A quick search whose prospect URL
+// names the channel differently than the submitted URL, e.g. by channel ID
+// instead of handle, is then not found and its result times out.
+func (c *Client) BulkQuickSearch(ctx context.Context, urls []string, opts *BulkQuickSearchOptions) ([]BulkQuickSearchResult, error) {
+	o := BulkQuickSearchOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = _defaultBulkConcurrency
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = _defaultBulkPollInterval
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = _defaultBulkTimeout
+	}
+
+	cc := c.WithContext(ctx)
+	b := &bulkQuickSearch{
+		c:       cc,
+		results: make([]BulkQuickSearchResult, len(urls)),
+	}
+
+	if c.options.dryRun == nil {
+		err := b.snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("failed to bulk quick search: %w", err)
+		}
+	}
+
+	b.submit(ctx, urls, o)
+	if c.options.dryRun != nil {
+		return b.results, ctx.Err()
+	}
+
+	err := b.poll(ctx, o)
+	return b.results, err
+}
+
+type bulkQuickSearch struct {
+	c       *Client
+	results []BulkQuickSearchResult
+
+	// existing are the quick searches from before the submission.
+	existing *QuickSearchIndex
+
+	mu      sync.Mutex
+	known   map[int]bool
+	claimed map[int]bool
+}
+
+// snapshot records the existing quick searches, so new ones can be told
+// apart when a creation does not return its ID.
+func (b *bulkQuickSearch) snapshot() error {
+	existing, err := b.c.GetQuickSearches()
+	if err != nil {
+		return err
+	}
+
+	b.existing = NewQuickSearchIndex(existing)
+	b.known = make(map[int]bool, len(existing))
+	b.claimed = make(map[int]bool)
+	for _, qs := range existing {
+		b.known[qs.ID] = true
+	}
+
+	return nil
+}
+
+func (b *bulkQuickSearch) submit(ctx context.Context, urls []string, o BulkQuickSearchOptions) {
+	sem := make(chan struct{}, o.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, url := range urls {
+		r := &b.results[i]
+		r.URL = url
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			r.Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			b.create(r, o)
+		}()
+	}
+	wg.Wait()
+}
+
+func (b *bulkQuickSearch) create(r *BulkQuickSearchResult, o BulkQuickSearchOptions) {
+	channel := o.Channel
+	if channel == 0 {
+		u, err := ParseChannelURL(r.URL)
+		if err != nil {
+			r.Err = fmt.Errorf("failed to create quick search: %w", err)
+			return
+		}
+		channel = u.Channel
+	}
+
+	if o.Reuse > 0 && b.existing != nil {
+		if qs := b.existing.reusable(r.URL, b.c.options.clock.Now(), o.Reuse); qs != nil {
+			r.update(qs)
+			return
+		}
+	}
+
+	// The snapshot stands in for a listing per URL when checking for
+	// reusable and duplicate quick searches.
+	qs, err := b.c.createQuickSearch(channel, r.URL, b.existing)
+	if err != nil {
+		r.Err = err
+		return
+	}
+
+	if b.c.options.dryRun != nil {
+		return
+	}
+
+	if qs.ID == 0 {
+		// Looked up by URL while polling.
+		r.Status = SearchStatusPending
+		return
+	}
+
+	b.mu.Lock()
+	b.claimed[qs.ID] = true
+	b.mu.Unlock()
+
+	r.update(qs)
+}
+
+// match assigns quick searches that neither existed before nor are
+// claimed by another result to the unresolved results for the same
+// channel, oldest first.
+func (b *bulkQuickSearch) match(quickSearches []QuickSearch) {
+	unresolved := make(map[string][]*BulkQuickSearchResult)
+	for i := range b.results {
+		if r := &b.results[i]; r.unresolved() {
+			k := channelKey(r.URL)
+			unresolved[k] = append(unresolved[k], r)
+		}
+	}
+
+	if len(unresolved) == 0 {
+		return
+	}
+
+	var created []*QuickSearch
+	for i, qs := range quickSearches {
+		if b.known[qs.ID] || b.claimed[qs.ID] || qs.Prospect == nil || qs.Prospect.URL == "" {
+			continue
+		}
+		created = append(created, &quickSearches[i])
+	}
+	sort.Slice(created, func(i, j int) bool {
+		return created[i].ID < created[j].ID
+	})
+
+	for _, qs := range created {
+		k := channelKey(qs.Prospect.URL)
+		rs := unresolved[k]
+		if len(rs) == 0 {
+			continue
+		}
+
+		b.claimed[qs.ID] = true
+		rs[0].update(qs)
+		unresolved[k] = rs[1:]
+	}
+}
+
+func (r *BulkQuickSearchResult) update(qs *QuickSearch) {
+	r.QuickSearchID = qs.ID
+	r.Status = qs.Status
+	r.Prospect = qs.Prospect
+}
+
+// unresolved reports whether the quick search was created without an ID
+// and has not been found yet.
+func (r *BulkQuickSearchResult) unresolved() bool {
+	return r.Err == nil && r.QuickSearchID == 0
+}
+
+func (r *BulkQuickSearchResult) pending() bool {
+	return r.Err == nil && (r.QuickSearchID == 0 || r.Status != SearchStatusFinished)
+}
+
+// poll checks the unfinished quick searches, and looks for the ones whose
+// creation returned no ID, until all have finished or the timeout has
+// passed. The first check is made right away.
+func (b *bulkQuickSearch) poll(ctx context.Context, o BulkQuickSearchOptions) error {
+	clk := b.c.options.clock
+	deadline := clk.Now().Add(o.Timeout)
+
+	for first := true; ; first = false {
+		var pending []*BulkQuickSearchResult
+		for i := range b.results {
+			if r := &b.results[i]; r.pending() {
+				pending = append(pending, r)
+			}
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		if !first {
+			if !clk.Now().Before(deadline) {
+				for _, r := range pending {
+					r.Err = ErrQuickSearchTimeout
+					if r.QuickSearchID == 0 {
+						r.Err = fmt.Errorf("failed to find created quick search: %w", ErrQuickSearchTimeout)
+					}
+				}
+				return nil
+			}
+
+			t := clk.Timer(o.PollInterval)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				for _, r := range pending {
+					r.Err = ctx.Err()
+				}
+				return ctx.Err()
+			}
+		}
+
+		// A single listing is cheaper than fetching every quick search.
+		// Failed polls are retried on the next tick.
+		quickSearches, err := b.c.GetQuickSearches()
+		if err != nil {
+			continue
+		}
+
+		byID := make(map[int]*BulkQuickSearchResult, len(pending))
+		for _, r := range pending {
+			if r.QuickSearchID != 0 {
+				byID[r.QuickSearchID] = r
+			}
+		}
+
+		for i, qs := range quickSearches {
+			if r, ok := byID[qs.ID]; ok {
+				r.update(&quickSearches[i])
+			}
+		}
+		b.match(quickSearches)
+	}
+}
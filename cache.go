@@ -0,0 +1,206 @@
+package prospety
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CacheEntry is a cached response body with the validators needed to
+// revalidate it once it expires.
+type CacheEntry struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Expires      time.Time `json:"expires"`
+}
+
+// Cache stores responses of GET requests keyed by the client's namespace
+// and their full URL. See the prospety/cache package for in-memory and
+// on-disk implementations.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, e *CacheEntry)
+	Delete(key string)
+
+	// DeletePrefix deletes every key starting with prefix.
+	DeletePrefix(prefix string)
+}
+
+// _defaultCacheTTLs are used for the operations that have no TTL set with
+// WithCacheTTL. Operations without a TTL are not cached.
+var _defaultCacheTTLs = map[string]time.Duration{
+	"GetChannels":  24 * time.Hour,
+	"GetChannel":   24 * time.Hour,
+	"GetSearch":    30 * time.Second,
+	"GetProspects": 5 * time.Minute,
+}
+
+// WithCache caches the responses of read operations. Entries are
+// revalidated with If-None-Match or If-Modified-Since when the server sent
+// an ETag or Last-Modified header, and invalidated when the client mutates
+// the resource they belong to.
+func WithCache(cache Cache) Option {
+	return func(option *options) error {
+		option.cache = cache
+		return nil
+	}
+}
+
+// WithCacheNamespace sets the prefix of the client's cache keys. Clients
+// sharing a Cache only see each other's responses when their namespaces
+// match. By default the namespace is derived from the API key, the name and
+// value of an EnvToken, or the path of a FileToken; clients with any other
+// TokenSource get a namespace of their own, so set one to share responses
+// between them or across restarts.
+func WithCacheNamespace(namespace string) Option {
+	return func(option *options) error {
+		if namespace == "" {
+			return errors.New("cache namespace must not be empty")
+		}
+
+		option.cacheNamespace = namespace
+		return nil
+	}
+}
+
+// cacheNamespace derives the default namespace from the identity of ts.
+// It is hashed so that keys do not reveal the API key.
+func cacheNamespace(ts TokenSource) (string, error) {
+	var id string
+	switch ts := ts.(type) {
+	case StaticToken:
+		id = "static:" + string(ts)
+	case EnvToken:
+		id = "env:" + string(ts) + "=" + os.Getenv(string(ts))
+	case *FileToken:
+		id = "file:" + ts.path
+	default:
+		b := make([]byte, 16)
+		_, err := rand.Read(b)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate cache namespace: %w", err)
+		}
+		id = "random:" + hex.EncodeToString(b)
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+func (c *Client) cacheKey(url string) string {
+	return c.options.cacheNamespace + ":" + url
+}
+
+// WithCacheTTL sets how long the responses of an operation, named like the
+// Client method (e.g. "GetSearch"), are served from the cache. A TTL of 0
+// disables caching for the operation.
+func WithCacheTTL(operation string, ttl time.Duration) Option {
+	return func(option *options) error {
+		if ttl < 0 {
+			return fmt.Errorf("cache TTL must not be negative: %s", ttl)
+		}
+
+		if option.cacheTTLs == nil {
+			option.cacheTTLs = make(map[string]time.Duration)
+		}
+		option.cacheTTLs[operation] = ttl
+		return nil
+	}
+}
+
+func (o *options) cacheTTL(operation string) time.Duration {
+	if ttl, ok := o.cacheTTLs[operation]; ok {
+		return ttl
+	}
+
+	return _defaultCacheTTLs[operation]
+}
+
+// cachedDo serves req from the cache when possible and stores the response
+// otherwise.
+func (c *Client) cachedDo(req *http.Request, path []string) ([]byte, error) {
+	cache := c.options.cache
+	op := describe(req.Method, path, req.URL.Query())
+	ttl := c.options.cacheTTL(op.Name)
+	if cache == nil || ttl == 0 {
+		return c.do(req, path)
+	}
+
+	key := c.cacheKey(req.URL.String())
+	now := c.options.clock.Now()
+
+	entry, ok := cache.Get(key)
+	if ok && now.Before(entry.Expires) {
+		return entry.Body, nil
+	}
+
+	if ok {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	data, header, err := c.doResponse(req, path)
+
+	var apiErr *APIError
+	if ok && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+		fresh := *entry
+		fresh.Expires = now.Add(ttl)
+		if etag := apiErr.Header.Get("ETag"); etag != "" {
+			fresh.ETag = etag
+		}
+		cache.Set(key, &fresh)
+
+		return fresh.Body, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Set(key, &CacheEntry{
+		Body:         data,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Expires:      now.Add(ttl),
+	})
+
+	return data, nil
+}
+
+// invalidate drops every cached response that a successful mutation of path
+// may have made stale: the resource itself, anything below it, and the
+// listing it belongs to.
+func (c *Client) invalidate(path []string) {
+	cache := c.options.cache
+	if cache == nil || len(path) == 0 {
+		return
+	}
+
+	collection := c.cacheKey(c.buildUrl(path[:1]))
+	cache.Delete(collection)
+	cache.DeletePrefix(collection + "?")
+
+	if len(path) < 2 {
+		return
+	}
+
+	if _, err := strconv.Atoi(path[1]); err != nil {
+		return
+	}
+
+	resource := c.cacheKey(c.buildUrl(path[:2]))
+	cache.Delete(resource)
+	cache.DeletePrefix(resource + "/")
+	cache.DeletePrefix(resource + "?")
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	prospety "github.com/bjornpagen/prospety-go"
+	"github.com/bjornpagen/prospety-go/internal/safefile"
+)
+
+// Disk stores one JSON file per entry in a directory, so the cache survives
+// restarts and can be shared by processes on the same machine. Errors are
+// treated as cache misses.
+type Disk struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+type diskItem struct {
+	Key   string               `json:"key"`
+	Entry *prospety.CacheEntry `json:"entry"`
+}
+
+var _ prospety.Cache = (*Disk)(nil)
+
+func NewDisk(dir string) (*Disk, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &Disk{
+		dir: dir,
+	}, nil
+}
+
+func (c *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *Disk) Get(key string) (*prospety.CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, err := readItem(c.path(key))
+	if err != nil || item.Key != key {
+		return nil, false
+	}
+
+	return item.Entry, true
+}
+
+func (c *Disk) Set(key string, e *prospety.CacheEntry) {
+	data, err := json.Marshal(&diskItem{
+		Key:   key,
+		Entry: e,
+	})
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	safefile.Write(c.path(key), data)
+}
+
+func (c *Disk) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	os.Remove(c.path(key))
+}
+
+// DeletePrefix has to read every entry, since file names are hashed.
+func (c *Disk) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		p := filepath.Join(c.dir, e.Name())
+		item, err := readItem(p)
+		if err != nil || strings.HasPrefix(item.Key, prefix) {
+			os.Remove(p)
+		}
+	}
+}
+
+func readItem(path string) (*diskItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &diskItem{}
+	err = json.Unmarshal(data, item)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Entry == nil {
+		return nil, errors.New("empty cache entry")
+	}
+
+	return item, nil
+}
+
+// Clear removes every entry.
+func (c *Disk) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+
+		return os.Remove(path)
+	})
+}
@@ -0,0 +1,236 @@
+package prospety_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	prospety "github.com/bjornpagen/prospety-go"
+	"github.com/bjornpagen/prospety-go/cache"
+)
+
+// fakeAPI serves searches and counts the requests for every path.
+type fakeAPI struct {
+	mu          sync.Mutex
+	hits        map[string]int
+	ifNoneMatch []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.hits[r.Method+" "+r.URL.Path]++
+	f.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		fmt.Fprint(w, `{}`)
+		return
+	}
+
+	switch rest := strings.TrimPrefix(r.URL.Path, "/searches"); {
+	case rest == "", strings.HasSuffix(rest, "/prospects"):
+		fmt.Fprint(w, `{"total": 0, "data": []}`)
+	case rest == "/1":
+		f.mu.Lock()
+		f.ifNoneMatch = append(f.ifNoneMatch, r.Header.Get("If-None-Match"))
+		f.mu.Unlock()
+
+		// The first revalidation hands out a new ETag for the same body.
+		switch r.Header.Get("If-None-Match") {
+		case `"v1"`:
+			w.Header().Set("ETag", `"v2"`)
+			w.WriteHeader(http.StatusNotModified)
+		case `"v2"`:
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, `{"id": 1, "title": "one"}`)
+		}
+	default:
+		fmt.Fprintf(w, `{"id": %s}`, strings.TrimPrefix(rest, "/"))
+	}
+}
+
+func (f *fakeAPI) count(method, path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.hits[method+" "+path]
+}
+
+func newCachedClient(t *testing.T) (*prospety.Client, *fakeAPI, *cache.LRU, *clock.Mock, string) {
+	t.Helper()
+
+	api := &fakeAPI{hits: make(map[string]int)}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	base, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lru := cache.NewLRU(100)
+	mock := clock.NewMock()
+	c, err := prospety.New("key",
+		prospety.WithBaseURL(base),
+		prospety.WithCache(lru),
+		prospety.WithCacheNamespace("ns"),
+		prospety.WithCacheTTL("GetSearches", time.Minute),
+		prospety.WithClock(mock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, api, lru, mock, "ns:" + srv.URL
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	c, api, lru, mock, prefix := newCachedClient(t)
+
+	get := func() {
+		t.Helper()
+
+		s, err := c.GetSearch(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Title != "one" {
+			t.Fatalf("title = %q, want %q", s.Title, "one")
+		}
+	}
+
+	get()
+	get()
+	if n := api.count("GET", "/searches/1"); n != 1 {
+		t.Fatalf("fresh entry: %d requests, want 1", n)
+	}
+
+	// A 304 serves the cached body, takes the new ETag and renews the
+	// entry.
+	mock.Add(31 * time.Second)
+	get()
+	get()
+	if n := api.count("GET", "/searches/1"); n != 2 {
+		t.Fatalf("revalidated entry: %d requests, want 2", n)
+	}
+
+	e, ok := lru.Get(prefix + "/searches/1")
+	if !ok || e.ETag != `"v2"` {
+		t.Fatalf("cache entry = %+v, want ETag %q", e, `"v2"`)
+	}
+
+	// A 304 without an ETag keeps the one the entry has.
+	mock.Add(31 * time.Second)
+	get()
+
+	e, _ = lru.Get(prefix + "/searches/1")
+	if e.ETag != `"v2"` {
+		t.Errorf("ETag = %q after a 304 without one, want %q", e.ETag, `"v2"`)
+	}
+
+	want := []string{"", `"v1"`, `"v2"`}
+	if strings.Join(api.ifNoneMatch, ",") != strings.Join(want, ",") {
+		t.Errorf("If-None-Match = %q, want %q", api.ifNoneMatch, want)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c, api, lru, _, prefix := newCachedClient(t)
+
+	// An entry of another client sharing the cache.
+	other := "other:" + strings.TrimPrefix(prefix, "ns:") + "/searches/1"
+	lru.Set(other, &prospety.CacheEntry{Body: []byte(`{}`), Expires: time.Now().Add(time.Hour)})
+
+	fill := func() {
+		t.Helper()
+
+		_, err := c.GetSearches()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range []int{1, 2, 10} {
+			_, err := c.GetSearch(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.GetProspects(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	cached := func(path string) bool {
+		_, ok := lru.Get(prefix + path)
+		return ok
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func() error
+		dropped  []string
+		retained []string
+	}{
+		{
+			name:     "StartSearch",
+			mutate:   func() error { return c.StartSearch(1) },
+			dropped:  []string{"/searches/1", "/searches/1/prospects"},
+			retained: []string{"/searches/2", "/searches/10", "/searches/10/prospects"},
+		},
+		{
+			name:     "DeleteSearch",
+			mutate:   func() error { return c.DeleteSearch(2) },
+			dropped:  []string{"/searches/2", "/searches/2/prospects"},
+			retained: []string{"/searches/1", "/searches/10", "/searches/1/prospects"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fill()
+			listings := api.count("GET", "/searches")
+			searches := api.count("GET", "/searches/10")
+			fill()
+			if api.count("GET", "/searches") != listings || api.count("GET", "/searches/10") != searches {
+				t.Fatal("responses were not served from the cache")
+			}
+
+			err := tt.mutate()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, p := range tt.dropped {
+				if cached(p) {
+					t.Errorf("%s is still cached", p)
+				}
+			}
+			for _, p := range tt.retained {
+				if !cached(p) {
+					t.Errorf("%s was dropped", p)
+				}
+			}
+			if _, ok := lru.Get(other); !ok {
+				t.Errorf("entry of another namespace was dropped")
+			}
+
+			// The listing, cached under its query string, is fetched again.
+			_, err = c.GetSearches()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := api.count("GET", "/searches"); n != listings+1 {
+				t.Errorf("listing was served from the cache after %s", tt.name)
+			}
+		})
+	}
+}
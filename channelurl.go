@@ -0,0 +1,333 @@
+package prospety
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidChannelURL is returned for strings that are not a YouTube
+// channel, YouTube video or Instagram profile URL.
+var ErrInvalidChannelURL = errors.New("invalid channel URL")
+
+// ChannelURLKind says which part of a channel a ChannelURL names.
+type ChannelURLKind int
+
+const (
+	// ChannelURLID is a YouTube channel ID, youtube.com/channel/UC….
+	ChannelURLID ChannelURLKind = iota + 1
+
+	// ChannelURLHandle is a YouTube handle, youtube.com/@name.
+	ChannelURLHandle
+
+	// ChannelURLCustom is a YouTube custom URL, youtube.com/c/name.
+	ChannelURLCustom
+
+	// ChannelURLUser is a legacy YouTube username, youtube.com/user/name.
+	ChannelURLUser
+
+	// ChannelURLVideo is a YouTube video. Quick searches for a video find
+	// the channel that uploaded it.
+	ChannelURLVideo
+
+	// ChannelURLProfile is an Instagram profile, instagram.com/name.
+	ChannelURLProfile
+
+	// ChannelURLLegacy is a legacy YouTube URL, youtube.com/name, which
+	// may lead to a custom URL or a username.
+	ChannelURLLegacy
+)
+
+func (k ChannelURLKind) String() string {
+	switch k {
+	case ChannelURLID:
+		return "channel ID"
+	case ChannelURLHandle:
+		return "handle"
+	case ChannelURLCustom:
+		return "custom URL"
+	case ChannelURLUser:
+		return "username"
+	case ChannelURLVideo:
+		return "video"
+	case ChannelURLProfile:
+		return "profile"
+	case ChannelURLLegacy:
+		return "legacy URL"
+	default:
+		return fmt.Sprintf("ChannelURLKind(%d)", int(k))
+	}
+}
+
+// ChannelURL is a parsed channel URL.
+type ChannelURL struct {
+	Channel ChannelType
+	Kind    ChannelURLKind
+
+	// ID is the channel ID, handle without "@", custom name, username,
+	// video ID or Instagram username, depending on Kind. Handles, names and
+	// usernames are case insensitive and lower case, except legacy names,
+	// which are kept as given.
+	ID string
+
+	// Raw is the URL as given, with "https://" added when it had no
+	// scheme.
+	Raw string
+
+	// partial is set when Raw has path segments or query parameters that
+	// were not needed to tell the channel, such as youtube.com/@name/videos.
+	partial bool
+}
+
+var (
+	_youTubeChannelID = regexp.MustCompile(`^UC[A-Za-z0-9_-]{22}$`)
+	_youTubeVideoID   = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	_youTubeHandle    = regexp.MustCompile(`^[\p{L}\p{M}\p{N}._·-]{3,30}$`)
+	_youTubeName      = regexp.MustCompile(`^[\p{L}\p{M}\p{N}._-]{1,100}$`)
+	_youTubeLegacy    = regexp.MustCompile(`^[\p{L}\p{M}\p{N}]{1,100}$`)
+	_instagramName    = regexp.MustCompile(`^[A-Za-z0-9._]{1,30}$`)
+)
+
+// _youTubeReserved are first path segments of youtube.com that are not
+// legacy custom URLs.
+var _youTubeReserved = map[string]bool{
+	"watch": true, "shorts": true, "embed": true, "live": true, "v": true,
+	"results": true, "feed": true, "playlist": true, "channel": true,
+	"c": true, "user": true, "account": true, "premium": true,
+	"gaming": true, "about": true, "t": true, "hashtag": true,
+	"redirect": true, "signin": true, "logout": true,
+}
+
+// _instagramReserved are first path segments of instagram.com that are not
+// profiles.
+var _instagramReserved = map[string]bool{
+	"p": true, "reel": true, "reels": true, "tv": true, "explore": true,
+	"stories": true, "accounts": true, "direct": true, "about": true,
+	"developer": true, "legal": true, "web": true,
+}
+
+// ParseChannelURL parses any of the URL forms of a YouTube channel or video
+// or an Instagram profile, with or without scheme and "www.". A bare channel
+// ID "UC…" or handle "@name" is taken to be YouTube.
+func ParseChannelURL(s string) (*ChannelURL, error) {
+	raw := strings.TrimSpace(s)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidChannelURL)
+	}
+
+	if _youTubeChannelID.MatchString(raw) {
+		return &ChannelURL{Channel: ChannelYouTube, Kind: ChannelURLID, ID: raw, Raw: raw}, nil
+	}
+
+	if strings.HasPrefix(raw, "@") {
+		res, _, err := parseYouTubePath([]string{raw}, nil, s)
+		if err != nil {
+			return nil, err
+		}
+		res.Raw = raw
+		return res, nil
+	}
+
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidChannelURL, s)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %q: unsupported scheme %q", ErrInvalidChannelURL, s, u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	host = strings.TrimPrefix(host, "m.")
+
+	segs := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	q := u.Query()
+
+	var res *ChannelURL
+	var used int
+	switch host {
+	case "youtube.com", "music.youtube.com":
+		res, used, err = parseYouTubePath(segs, q, s)
+	case "youtu.be":
+		if len(segs) == 0 {
+			return nil, fmt.Errorf("%w: %q: missing video ID", ErrInvalidChannelURL, s)
+		}
+		res, err = youTubeVideo(segs[0], s)
+		used = 1
+	case "instagram.com", "instagr.am":
+		res, err = parseInstagramPath(segs, s)
+		used = 1
+	default:
+		return nil, fmt.Errorf("%w: %q: not a YouTube or Instagram URL", ErrInvalidChannelURL, s)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Kind == ChannelURLVideo {
+		q.Del("v")
+	}
+	res.Raw = raw
+	res.partial = len(segs) > used || len(q) > 0
+
+	return res, nil
+}
+
+// parseYouTubePath also returns how many segments of the path it used.
+func parseYouTubePath(segs []string, q url.Values, s string) (*ChannelURL, int, error) {
+	if len(segs) == 0 {
+		return nil, 0, fmt.Errorf("%w: %q: missing channel", ErrInvalidChannelURL, s)
+	}
+
+	first := segs[0]
+	switch {
+	case strings.HasPrefix(first, "@"):
+		handle := strings.TrimPrefix(first, "@")
+		if !_youTubeHandle.MatchString(handle) {
+			return nil, 0, fmt.Errorf("%w: %q: invalid handle", ErrInvalidChannelURL, s)
+		}
+		return &ChannelURL{Channel: ChannelYouTube, Kind: ChannelURLHandle, ID: strings.ToLower(handle)}, 1, nil
+	case first == "watch":
+		u, err := youTubeVideo(q.Get("v"), s)
+		return u, 1, err
+	case first == "shorts", first == "embed", first == "live", first == "v":
+		if len(segs) < 2 {
+			return nil, 0, fmt.Errorf("%w: %q: missing video ID", ErrInvalidChannelURL, s)
+		}
+		u, err := youTubeVideo(segs[1], s)
+		return u, 2, err
+	case first == "channel":
+		if len(segs) < 2 || !_youTubeChannelID.MatchString(segs[1]) {
+			return nil, 0, fmt.Errorf("%w: %q: invalid channel ID", ErrInvalidChannelURL, s)
+		}
+		return &ChannelURL{Channel: ChannelYouTube, Kind: ChannelURLID, ID: segs[1]}, 2, nil
+	case first == "c", first == "user":
+		if len(segs) < 2 || !_youTubeName.MatchString(segs[1]) {
+			return nil, 0, fmt.Errorf("%w: %q: invalid channel name", ErrInvalidChannelURL, s)
+		}
+
+		kind := ChannelURLCustom
+		if first == "user" {
+			kind = ChannelURLUser
+		}
+		return &ChannelURL{Channel: ChannelYouTube, Kind: kind, ID: strings.ToLower(segs[1])}, 2, nil
+	// Legacy names are letters and digits only, which also keeps files
+	// such as youtube.com/robots.txt from passing as channels.
+	case !_youTubeReserved[strings.ToLower(first)] && _youTubeLegacy.MatchString(first):
+		return &ChannelURL{Channel: ChannelYouTube, Kind: ChannelURLLegacy, ID: first}, 1, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: %q: not a channel or video", ErrInvalidChannelURL, s)
+	}
+}
+
+func youTubeVideo(id, s string) (*ChannelURL, error) {
+	if !_youTubeVideoID.MatchString(id) {
+		return nil, fmt.Errorf("%w: %q: invalid video ID", ErrInvalidChannelURL, s)
+	}
+
+	return &ChannelURL{Channel: ChannelYouTube, Kind: ChannelURLVideo, ID: id}, nil
+}
+
+func parseInstagramPath(segs []string, s string) (*ChannelURL, error) {
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("%w: %q: missing profile", ErrInvalidChannelURL, s)
+	}
+
+	name := segs[0]
+	if _instagramReserved[strings.ToLower(name)] || !_instagramName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q: not a profile", ErrInvalidChannelURL, s)
+	}
+
+	return &ChannelURL{Channel: ChannelInstagram, Kind: ChannelURLProfile, ID: strings.ToLower(name)}, nil
+}
+
+// String returns the canonical URL.
+func (u *ChannelURL) String() string {
+	switch u.Kind {
+	case ChannelURLID:
+		return "https://www.youtube.com/channel/" + u.ID
+	case ChannelURLHandle:
+		return "https://www.youtube.com/@" + url.PathEscape(u.ID)
+	case ChannelURLCustom:
+		return "https://www.youtube.com/c/" + url.PathEscape(u.ID)
+	case ChannelURLUser:
+		return "https://www.youtube.com/user/" + url.PathEscape(u.ID)
+	case ChannelURLLegacy:
+		return "https://www.youtube.com/" + url.PathEscape(u.ID)
+	case ChannelURLVideo:
+		return "https://www.youtube.com/watch?v=" + u.ID
+	case ChannelURLProfile:
+		return "https://www.instagram.com/" + u.ID
+	default:
+		return ""
+	}
+}
+
+// requestURL is the URL to send to the API: the canonical URL, or Raw when
+// it has parts the canonical URL would drop.
+func (u *ChannelURL) requestURL() string {
+	if u.partial && u.Raw != "" {
+		return u.Raw
+	}
+
+	return u.String()
+}
+
+// Key identifies what u names: two URLs with the same key are the same
+// channel or video. Different kinds of URL of one channel have different
+// keys.
+func (u *ChannelURL) Key() string {
+	platform := "youtube"
+	if u.Channel == ChannelInstagram {
+		platform = "instagram"
+	}
+
+	id := u.ID
+	var kind string
+	switch u.Kind {
+	case ChannelURLID:
+		kind = "channel"
+	case ChannelURLHandle:
+		kind = "handle"
+	case ChannelURLCustom:
+		kind = "custom"
+	case ChannelURLUser:
+		kind = "user"
+	case ChannelURLVideo:
+		kind = "video"
+	case ChannelURLProfile:
+		kind = "profile"
+	case ChannelURLLegacy:
+		kind = "legacy"
+		id = strings.ToLower(id)
+	}
+
+	return platform + ":" + kind + ":" + id
+}
+
+// normalizeReferences parses the reference channels of a similar search and
+// returns them in canonical form.
+func normalizeReferences(refs []string) ([]string, error) {
+	res := make([]string, len(refs))
+	for i, ref := range refs {
+		u, err := ParseChannelURL(ref)
+		if err != nil {
+			return nil, fmt.Errorf("reference %d: %w", i, err)
+		}
+
+		if u.Channel != ChannelYouTube {
+			return nil, fmt.Errorf("reference %d: %q is not a YouTube channel", i, ref)
+		}
+
+		res[i] = u.requestURL()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,77 @@
+package prospety
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseChannelURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		kind ChannelURLKind
+		id   string
+	}{
+		{"UC0123456789abcdefghijkl", ChannelURLID, "UC0123456789abcdefghijkl"},
+		{"https://www.youtube.com/channel/UC0123456789abcdefghijkl", ChannelURLID, "UC0123456789abcdefghijkl"},
+		{"@FooGaming", ChannelURLHandle, "foogaming"},
+		{"youtube.com/@Foo.Gaming/videos", ChannelURLHandle, "foo.gaming"},
+		{"m.youtube.com/@ゲーム実況", ChannelURLHandle, "ゲーム実況"},
+		{"youtube.com/c/Foo_Gaming", ChannelURLCustom, "foo_gaming"},
+		{"http://youtube.com/user/foo.gaming", ChannelURLUser, "foo.gaming"},
+		{"https://youtu.be/dQw4w9WgXcQ", ChannelURLVideo, "dQw4w9WgXcQ"},
+		{"youtube.com/watch?v=dQw4w9WgXcQ&t=10", ChannelURLVideo, "dQw4w9WgXcQ"},
+		{"youtube.com/shorts/dQw4w9WgXcQ", ChannelURLVideo, "dQw4w9WgXcQ"},
+		{"instagram.com/Foo.Gaming", ChannelURLProfile, "foo.gaming"},
+		{"youtube.com/FooGaming", ChannelURLLegacy, "FooGaming"},
+		{"youtube.com/Gamer2000/videos", ChannelURLLegacy, "Gamer2000"},
+		{"youtube.com/Müller", ChannelURLLegacy, "Müller"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			u, err := ParseChannelURL(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if u.Kind != tt.kind || u.ID != tt.id {
+				t.Errorf("got %s %q, want %s %q", u.Kind, u.ID, tt.kind, tt.id)
+			}
+		})
+	}
+}
+
+func TestParseChannelURLErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"example.com/@foo",
+		"ftp://youtube.com/@foo",
+		"youtube.com",
+		"youtube.com/@ab",
+		"youtube.com/channel/UC123",
+		"youtube.com/watch?v=short",
+		"youtube.com/shorts",
+		"youtu.be/",
+		"youtube.com/c/",
+		"instagram.com/p/Cx1",
+		"instagram.com/explore",
+
+		// Files and pages of youtube.com are not legacy channel URLs.
+		"youtube.com/robots.txt",
+		"https://www.youtube.com/favicon.ico",
+		"youtube.com/feed/subscriptions",
+		"youtube.com/results?search_query=foo",
+		"youtube.com/foo-bar",
+		"youtube.com/foo_bar",
+	}
+
+	for _, in := range tests {
+		t.Run(in, func(t *testing.T) {
+			u, err := ParseChannelURL(in)
+			if !errors.Is(err, ErrInvalidChannelURL) {
+				t.Errorf("got %+v, %v, want %v", u, err, ErrInvalidChannelURL)
+			}
+		})
+	}
+}
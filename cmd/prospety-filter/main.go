@@ -0,0 +1,115 @@
+// Command prospety-filter filters prospects with a filter expression.
+//
+// It reads a JSON array of prospects from stdin, or fetches the prospects
+// of a search with -search using the API key in PROSPETY_API_KEY, and
+// writes the matching prospects to stdout as a JSON array:
+//
+//	prospety-filter -search 123 'subscribers > 50k and email != ""'
+//	prospety-filter 'country in ("US", "GB")' < prospects.json
+//
+// -fields lists the fields expressions can refer to.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	prospety "github.com/bjornpagen/prospety-go"
+	"github.com/bjornpagen/prospety-go/filter"
+)
+
+func main() {
+	search := flag.Int("search", 0, "fetch the prospects of this search instead of reading stdin")
+	listFields := flag.Bool("fields", false, "list the fields and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-search id] expression\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *listFields {
+		printFields()
+		return
+	}
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	err := run(flag.Arg(0), *search)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "prospety-filter: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(expr string, search int) error {
+	f, err := filter.Parse(expr)
+	if err != nil {
+		var syntaxErr *filter.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("%s\n  %s\n  %s^", syntaxErr.Msg, expr, strings.Repeat(" ", syntaxErr.Pos))
+		}
+		return err
+	}
+
+	prospects, err := load(search)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	res := f.Apply(prospects)
+	if res == nil {
+		res = []prospety.Prospect{}
+	}
+
+	return enc.Encode(res)
+}
+
+func load(search int) ([]prospety.Prospect, error) {
+	if search != 0 {
+		c, err := prospety.New("", prospety.WithTokenSource(prospety.EnvToken("PROSPETY_API_KEY")))
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetProspects(search)
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prospects: %w", err)
+	}
+
+	var prospects []prospety.Prospect
+	err = json.Unmarshal(data, &prospects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prospects: %w", err)
+	}
+
+	return prospects, nil
+}
+
+func printFields() {
+	fields := filter.Fields()
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("%-16s %s\n", name, fields[name])
+	}
+}
@@ -0,0 +1,68 @@
+package prospety
+
+import (
+	"net/http"
+	"sync"
+)
+
+// WithCoalescing makes concurrent identical GET requests share a single
+// HTTP call and rate limit token. Requests are identical when their method
+// and full URL, including query parameters, match. Waiting callers get the
+// result of the first caller, including its error if that caller's context
+// was cancelled.
+func WithCoalescing() Option {
+	return func(option *options) error {
+		option.inflight = &inflight{
+			calls: make(map[string]*inflightCall),
+		}
+		return nil
+	}
+}
+
+type inflight struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+type inflightCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// do runs fn once for all concurrent callers with the same key.
+func (f *inflight) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	f.mu.Lock()
+	if call, ok := f.calls[key]; ok {
+		f.mu.Unlock()
+		<-call.done
+		return call.data, call.err
+	}
+
+	call := &inflightCall{
+		done: make(chan struct{}),
+	}
+	f.calls[key] = call
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	call.data, call.err = fn()
+	return call.data, call.err
+}
+
+func (c *Client) coalescedDo(req *http.Request, path []string) ([]byte, error) {
+	if c.options.inflight == nil {
+		return c.cachedDo(req, path)
+	}
+
+	return c.options.inflight.do(req.Method+" "+req.URL.String(), func() ([]byte, error) {
+		return c.cachedDo(req, path)
+	})
+}
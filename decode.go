@@ -0,0 +1,462 @@
+package prospety
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DriftKind says how a response differed from the types it was decoded into.
+type DriftKind int
+
+const (
+	// DriftUnknownField is a field the client has no field for.
+	DriftUnknownField DriftKind = iota
+
+	// DriftCoerced is a value of the wrong type that was converted, e.g. a
+	// number sent as a string.
+	DriftCoerced
+
+	// DriftDropped is a value of the wrong type that could not be converted
+	// and was decoded as the zero value.
+	DriftDropped
+
+	// DriftShape is a listing sent as a bare array instead of an object
+	// with the items under "data".
+	DriftShape
+)
+
+func (k DriftKind) String() string {
+	switch k {
+	case DriftUnknownField:
+		return "unknown field"
+	case DriftCoerced:
+		return "coerced"
+	case DriftDropped:
+		return "dropped"
+	case DriftShape:
+		return "shape"
+	default:
+		return fmt.Sprintf("DriftKind(%d)", int(k))
+	}
+}
+
+// SchemaDrift is one difference between a response and the client's types.
+type SchemaDrift struct {
+	// Path locates the value, e.g. "data[3].subscribers".
+	Path   string
+	Kind   DriftKind
+	Detail string
+}
+
+func (d SchemaDrift) String() string {
+	if d.Detail == "" {
+		return fmt.Sprintf("%s: %s", d.Path, d.Kind)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", d.Path, d.Kind, d.Detail)
+}
+
+// SchemaDriftError reports that a response of an operation no longer
+// matches the client's types. With WithStrictDecoding it is returned as an
+// error; otherwise it is passed to the handler set with
+// WithSchemaDriftHandler, if any, after the response was decoded leniently.
+type SchemaDriftError struct {
+	// Operation is the Client method, e.g. "GetProspects".
+	Operation string
+	Drift     []SchemaDrift
+
+	// Err is the decoding error, if decoding failed.
+	Err error
+}
+
+func (e *SchemaDriftError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "schema drift in %s response", e.Operation)
+
+	for i, d := range e.Drift {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(d.String())
+	}
+
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %s", e.Err)
+	}
+
+	return b.String()
+}
+
+func (e *SchemaDriftError) Unwrap() error {
+	return e.Err
+}
+
+// WithStrictDecoding makes any difference between a response and the
+// client's types, including fields the client does not know, fail the call
+// with a *SchemaDriftError. Use it against recorded fixtures to find out
+// when the API changed.
+//
+// By default responses are decoded leniently: unknown fields are ignored,
+// numbers sent as strings and the like are converted, values that cannot be
+// converted and unparseable timestamps are left zero, and listings sent as a
+// bare array are accepted.
+func WithStrictDecoding() Option {
+	return func(option *options) error {
+		option.strictDecoding = true
+		return nil
+	}
+}
+
+// WithSchemaDriftHandler calls fn whenever a response is decoded leniently
+// despite differing from the client's types, e.g. to log a warning.
+func WithSchemaDriftHandler(fn func(err *SchemaDriftError)) Option {
+	return func(option *options) error {
+		option.driftHandler = fn
+		return nil
+	}
+}
+
+// decode decodes the response data of op into v.
+func (c *Client) decode(op string, data []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var raw any
+	err := d.Decode(&raw)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	w := &driftWalker{}
+	normalized := w.normalize(raw, reflect.TypeOf(v), "")
+
+	if c.options.strictDecoding {
+		if len(w.drift) > 0 {
+			return &SchemaDriftError{
+				Operation: op,
+				Drift:     w.drift,
+			}
+		}
+
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.DisallowUnknownFields()
+		err = d.Decode(v)
+		if err != nil {
+			return &SchemaDriftError{
+				Operation: op,
+				Err:       err,
+			}
+		}
+
+		return nil
+	}
+
+	if len(w.drift) > 0 {
+		data, err = json.Marshal(normalized)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if len(w.drift) > 0 && c.options.driftHandler != nil {
+		c.options.driftHandler(&SchemaDriftError{
+			Operation: op,
+			Drift:     w.drift,
+		})
+	}
+
+	return nil
+}
+
+var (
+	_timeType        = reflect.TypeOf(Time{})
+	_rawJSONType     = reflect.TypeOf(json.RawMessage{})
+	_jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	_textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func unmarshals(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(_jsonUnmarshaler) || p.Implements(_textUnmarshaler)
+}
+
+// driftWalker compares a decoded JSON value with the type it is meant for
+// and converts what it can.
+type driftWalker struct {
+	drift []SchemaDrift
+}
+
+func (w *driftWalker) report(path string, kind DriftKind, format string, args ...any) {
+	if path == "" {
+		path = "."
+	}
+
+	w.drift = append(w.drift, SchemaDrift{
+		Path:   path,
+		Kind:   kind,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// normalize returns v converted to fit t. Values that cannot be made to fit
+// are replaced with nil, which decodes as the zero value.
+func (w *driftWalker) normalize(v any, t reflect.Type, path string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	switch {
+	case t == _timeType:
+		return w.normalizeTime(v, path)
+	case t == _rawJSONType, t.Kind() == reflect.Interface:
+		return v
+	}
+
+	// Types that decode themselves, like time.Time, get their value as is
+	// unless it is an object whose fields can be checked.
+	if _, ok := v.(map[string]any); !ok && unmarshals(t) {
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return w.normalizeStruct(v, t, path)
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]any)
+		if !ok {
+			w.report(path, DriftDropped, "expected array, got %s", jsonKind(v))
+			return nil
+		}
+
+		for i, item := range items {
+			items[i] = w.normalize(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+		return items
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			w.report(path, DriftDropped, "expected object, got %s", jsonKind(v))
+			return nil
+		}
+
+		for _, k := range sortedKeys(m) {
+			m[k] = w.normalize(m[k], t.Elem(), joinPath(path, k))
+		}
+		return m
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return w.normalizeNumber(v, true, path)
+	case reflect.Float32, reflect.Float64:
+		return w.normalizeNumber(v, false, path)
+	case reflect.String:
+		switch v := v.(type) {
+		case string:
+			return v
+		case json.Number:
+			w.report(path, DriftCoerced, "number %s as string", v)
+			return v.String()
+		case bool:
+			w.report(path, DriftCoerced, "boolean %t as string", v)
+			return strconv.FormatBool(v)
+		}
+	case reflect.Bool:
+		switch v := v.(type) {
+		case bool:
+			return v
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				w.report(path, DriftCoerced, "string %q as boolean", v)
+				return b
+			}
+		case json.Number:
+			if f, err := v.Float64(); err == nil && (f == 0 || f == 1) {
+				w.report(path, DriftCoerced, "number %s as boolean", v)
+				return f == 1
+			}
+		}
+	default:
+		return v
+	}
+
+	w.report(path, DriftDropped, "expected %s, got %s", t.Kind(), jsonKind(v))
+	return nil
+}
+
+func (w *driftWalker) normalizeStruct(v any, t reflect.Type, path string) any {
+	if items, ok := v.([]any); ok {
+		// Listings are objects with the items under "data". Accept the
+		// items on their own.
+		data, ok := structFields(t)["data"]
+		if !ok || data.Kind() != reflect.Slice {
+			w.report(path, DriftDropped, "expected object, got array")
+			return nil
+		}
+
+		w.report(path, DriftShape, "bare array instead of object")
+		v = map[string]any{
+			"total": json.Number(strconv.Itoa(len(items))),
+			"data":  items,
+		}
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		w.report(path, DriftDropped, "expected object, got %s", jsonKind(v))
+		return nil
+	}
+
+	fields := structFields(t)
+	for _, k := range sortedKeys(m) {
+		ft, ok := fields[k]
+		if !ok {
+			ft, ok = foldField(fields, k)
+		}
+		if !ok {
+			w.report(joinPath(path, k), DriftUnknownField, "")
+			continue
+		}
+
+		m[k] = w.normalize(m[k], ft, joinPath(path, k))
+	}
+
+	return m
+}
+
+func (w *driftWalker) normalizeNumber(v any, integer bool, path string) any {
+	var n json.Number
+	switch v := v.(type) {
+	case json.Number:
+		n = v
+	case string:
+		s := strings.TrimSpace(strings.ReplaceAll(v, ",", ""))
+		if s == "" {
+			w.report(path, DriftCoerced, "empty string as number")
+			return nil
+		}
+
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			w.report(path, DriftDropped, "string %q as number", v)
+			return nil
+		}
+
+		w.report(path, DriftCoerced, "string %q as number", v)
+		n = json.Number(s)
+	default:
+		w.report(path, DriftDropped, "expected number, got %s", jsonKind(v))
+		return nil
+	}
+
+	if !integer {
+		return n
+	}
+
+	if _, err := n.Int64(); err == nil {
+		return n
+	}
+
+	f, err := n.Float64()
+	if err != nil || f != math.Trunc(f) {
+		w.report(path, DriftDropped, "number %s as integer", n)
+		return nil
+	}
+
+	w.report(path, DriftCoerced, "number %s as integer", n)
+	return json.Number(strconv.FormatFloat(f, 'f', 0, 64))
+}
+
+func (w *driftWalker) normalizeTime(v any, path string) any {
+	switch v := v.(type) {
+	case string:
+		_, err := ParseTime(v, time.Now())
+		if err != nil {
+			w.report(path, DriftDropped, "%s", err)
+			return nil
+		}
+	case json.Number:
+	default:
+		w.report(path, DriftDropped, "expected time, got %s", jsonKind(v))
+		return nil
+	}
+
+	return v
+}
+
+var _structFields sync.Map // reflect.Type -> map[string]reflect.Type
+
+func structFields(t reflect.Type) map[string]reflect.Type {
+	if fields, ok := _structFields.Load(t); ok {
+		return fields.(map[string]reflect.Type)
+	}
+
+	fields, _ := _structFields.LoadOrStore(t, jsonFields(t))
+	return fields.(map[string]reflect.Type)
+}
+
+// foldField finds the field encoding/json would decode name into when no
+// field matches exactly.
+func foldField(fields map[string]reflect.Type, name string) (reflect.Type, bool) {
+	for k, ft := range fields {
+		if strings.EqualFold(k, name) {
+			return ft, true
+		}
+	}
+
+	return nil, false
+}
+
+// sortedKeys returns the keys of m in order, so drift is reported in the
+// same order every time.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
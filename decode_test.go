@@ -0,0 +1,220 @@
+package prospety
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var _decodeTests = []struct {
+	name  string
+	data  string
+	drift []string
+	check func(t *testing.T, res *getProspectsResponse)
+}{
+	{
+		name: "clean",
+		data: `{"total": 1, "data": [{"name": "a", "subscribers": 1200, "engagement": 0.5}]}`,
+		check: func(t *testing.T, res *getProspectsResponse) {
+			p := res.Data[0]
+			if p.Name != "a" || p.Subscribers != 1200 || p.Engagement != 0.5 {
+				t.Errorf("got %q, %d, %g", p.Name, p.Subscribers, p.Engagement)
+			}
+		},
+	},
+	{
+		name: "numbers as strings",
+		data: `{"total": "1", "data": [{"subscribers": "1,200", "engagement": "0.5"}]}`,
+		drift: []string{
+			"data[0].engagement: coerced",
+			"data[0].subscribers: coerced",
+			"total: coerced",
+		},
+		check: func(t *testing.T, res *getProspectsResponse) {
+			p := res.Data[0]
+			if res.Total != 1 || p.Subscribers != 1200 || p.Engagement != 0.5 {
+				t.Errorf("got total %d, subscribers %d, engagement %g", res.Total, p.Subscribers, p.Engagement)
+			}
+		},
+	},
+	{
+		name: "floats as integers",
+		data: `{"total": 1.0, "data": [{"subscribers": 1.5e3, "total_videos": 2.5}]}`,
+		drift: []string{
+			"data[0].subscribers: coerced",
+			"data[0].total_videos: dropped",
+			"total: coerced",
+		},
+		check: func(t *testing.T, res *getProspectsResponse) {
+			p := res.Data[0]
+			if res.Total != 1 || p.Subscribers != 1500 || p.TotalVideos != 0 {
+				t.Errorf("got total %d, subscribers %d, total videos %d", res.Total, p.Subscribers, p.TotalVideos)
+			}
+		},
+	},
+	{
+		name:  "bare array",
+		data:  `[{"name": "a"}, {"name": "b"}]`,
+		drift: []string{".: shape"},
+		check: func(t *testing.T, res *getProspectsResponse) {
+			if res.Total != 2 || len(res.Data) != 2 || res.Data[1].Name != "b" {
+				t.Errorf("got %+v", res)
+			}
+		},
+	},
+	{
+		name:  "unknown field",
+		data:  `{"total": 1, "data": [{"name": "a", "brand_new": {"x": 1}}]}`,
+		drift: []string{"data[0].brand_new: unknown field"},
+		check: func(t *testing.T, res *getProspectsResponse) {
+			if got := string(res.Data[0].Extra["brand_new"]); got != `{"x":1}` {
+				t.Errorf("Extra[brand_new] = %s, want %s", got, `{"x":1}`)
+			}
+		},
+	},
+	{
+		// Coercing re-encodes the response, which must keep the fields
+		// that end up in Extra.
+		name: "extra with coercion",
+		data: `{"total": 1, "data": [{"subscribers": "10", "brand_new": [1, "two"]}]}`,
+		drift: []string{
+			"data[0].brand_new: unknown field",
+			"data[0].subscribers: coerced",
+		},
+		check: func(t *testing.T, res *getProspectsResponse) {
+			p := res.Data[0]
+			if p.Subscribers != 10 {
+				t.Errorf("subscribers = %d, want 10", p.Subscribers)
+			}
+			if got := string(p.Extra["brand_new"]); got != `[1,"two"]` {
+				t.Errorf("Extra[brand_new] = %s, want %s", got, `[1,"two"]`)
+			}
+		},
+	},
+	{
+		name: "times",
+		data: `{"total": 1, "data": [{"created_at": "2024-06-01 12:00:00", "last_video": 1717243200}]}`,
+		check: func(t *testing.T, res *getProspectsResponse) {
+			want := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			p := res.Data[0]
+			if !p.CreatedAt.Equal(want) || !p.LastVideo.Equal(want) {
+				t.Errorf("got created at %s, last video %s, want %s", p.CreatedAt, p.LastVideo, want)
+			}
+		},
+	},
+	{
+		name: "bad times",
+		data: `{"total": 1, "data": [{"created_at": true, "last_video": "some time"}]}`,
+		drift: []string{
+			"data[0].created_at: dropped",
+			"data[0].last_video: dropped",
+		},
+		check: func(t *testing.T, res *getProspectsResponse) {
+			p := res.Data[0]
+			if !p.CreatedAt.IsZero() || !p.LastVideo.IsZero() {
+				t.Errorf("got created at %s, last video %s, want zero", p.CreatedAt, p.LastVideo)
+			}
+		},
+	},
+}
+
+func driftStrings(drift []SchemaDrift) []string {
+	res := make([]string, len(drift))
+	for i, d := range drift {
+		res[i] = fmt.Sprintf("%s: %s", d.Path, d.Kind)
+	}
+
+	return res
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestDecodeLenient(t *testing.T) {
+	for _, tt := range _decodeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported *SchemaDriftError
+			c, err := New("key", WithSchemaDriftHandler(func(err *SchemaDriftError) {
+				reported = err
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := &getProspectsResponse{}
+			err = c.decode("GetProspects", []byte(tt.data), res)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var drift []string
+			if reported != nil {
+				drift = driftStrings(reported.Drift)
+			}
+			if !sameStrings(drift, tt.drift) {
+				t.Errorf("drift = %q, want %q", drift, tt.drift)
+			}
+
+			tt.check(t, res)
+		})
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	c, err := New("key", WithStrictDecoding())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range _decodeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &getProspectsResponse{}
+			err := c.decode("GetProspects", []byte(tt.data), res)
+
+			if len(tt.drift) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				tt.check(t, res)
+				return
+			}
+
+			var de *SchemaDriftError
+			if !errors.As(err, &de) {
+				t.Fatalf("decode error = %v, want a *SchemaDriftError", err)
+			}
+			if de.Operation != "GetProspects" {
+				t.Errorf("operation = %q, want %q", de.Operation, "GetProspects")
+			}
+			if drift := driftStrings(de.Drift); !sameStrings(drift, tt.drift) {
+				t.Errorf("drift = %q, want %q", drift, tt.drift)
+			}
+		})
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithStrictDecoding()}} {
+		c, err := New("key", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = c.decode("GetProspects", []byte(`{"total": 1,`), &getProspectsResponse{})
+		if err == nil {
+			t.Errorf("decode of truncated JSON succeeded")
+		}
+	}
+}
@@ -0,0 +1,397 @@
+// Package dedupe merges the prospects of overlapping searches into one
+// record per channel.
+//
+//	set := dedupe.New()
+//	for _, id := range searchIDs {
+//		prospects, err := c.GetProspects(id)
+//		...
+//		set.Add(dedupe.Source{SearchID: id, Time: time.Now()}, prospects)
+//	}
+//	merged := set.Prospects()
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+// Source is where a batch of prospects came from.
+type Source struct {
+	SearchID int
+
+	// Time is when the prospects were fetched. Stats of the most recent
+	// source win when records are merged; batches with equal times are
+	// ordered by when they were added.
+	Time time.Time
+}
+
+// Prospect is a channel merged from every record of it.
+type Prospect struct {
+	prospety.Prospect
+
+	// Emails are all emails seen for the channel. Prospect.Email is the
+	// one from the freshest record that had one.
+	Emails []string `json:"emails"`
+
+	// SearchIDs are the searches the channel was found by, in ascending
+	// order.
+	SearchIDs []int `json:"search_ids"`
+
+	// Seen is the time of the freshest record.
+	Seen time.Time `json:"seen"`
+}
+
+// merged are the fields Prospect adds to prospety.Prospect. The methods of
+// the embedded prospety.Prospect would otherwise marshal only its own
+// fields.
+type merged struct {
+	Emails    []string  `json:"emails"`
+	SearchIDs []int     `json:"search_ids"`
+	Seen      time.Time `json:"seen"`
+}
+
+func (p Prospect) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(p.Prospect)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(merged{
+		Emails:    p.Emails,
+		SearchIDs: p.SearchIDs,
+		Seen:      p.Seen,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshalling into fields adds to it.
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
+
+func (p *Prospect) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, &p.Prospect)
+	if err != nil {
+		return err
+	}
+
+	var m merged
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return err
+	}
+
+	p.Emails = m.Emails
+	p.SearchIDs = m.SearchIDs
+	p.Seen = m.Seen
+
+	for _, k := range []string{"emails", "search_ids", "seen"} {
+		delete(p.Extra, k)
+	}
+	if len(p.Extra) == 0 {
+		p.Extra = nil
+	}
+
+	return nil
+}
+
+type entry struct {
+	Prospect
+
+	// seq is the position of the channel's first record, kept across
+	// merges.
+	seq     int
+	keys    []string
+	removed bool
+}
+
+// Set accumulates prospects and merges the records of the same channel.
+type Set struct {
+	entries []*entry
+	byKey   map[string]*entry
+	seq     int
+}
+
+func New() *Set {
+	return &Set{
+		byKey: make(map[string]*entry),
+	}
+}
+
+// Add merges prospects from src into the set. Prospects without a URL or
+// channel ID cannot be matched and are kept as they are.
+func (s *Set) Add(src Source, prospects []prospety.Prospect) {
+	for i := range prospects {
+		s.add(src, &prospects[i])
+	}
+}
+
+func (s *Set) add(src Source, p *prospety.Prospect) {
+	s.seq++
+	e := &entry{
+		Prospect: Prospect{
+			Prospect:  *p,
+			SearchIDs: []int{src.SearchID},
+			Seen:      src.Time,
+		},
+		seq:  s.seq,
+		keys: p.Identities(),
+	}
+	e.Links = dedupeLinks(nil, p.Links)
+	if p.Email != "" {
+		e.Emails = []string{p.Email}
+	}
+
+	// The record may join several entries that were not known to be the
+	// same channel until now.
+	var matches []*entry
+	for _, k := range e.keys {
+		if m, ok := s.byKey[k]; ok && !containsEntry(matches, m) {
+			matches = append(matches, m)
+		}
+	}
+
+	for _, m := range matches {
+		e = merge(m, e)
+		m.removed = true
+	}
+
+	s.entries = append(s.entries, e)
+	for _, k := range e.keys {
+		s.byKey[k] = e
+	}
+}
+
+func containsEntry(entries []*entry, e *entry) bool {
+	for _, x := range entries {
+		if x == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the number of distinct channels.
+func (s *Set) Len() int {
+	n := 0
+	for _, e := range s.entries {
+		if !e.removed {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Prospects returns the merged prospects in the order their channels were
+// first added.
+func (s *Set) Prospects() []Prospect {
+	var live []*entry
+	for _, e := range s.entries {
+		if !e.removed {
+			live = append(live, e)
+		}
+	}
+
+	sort.SliceStable(live, func(i, j int) bool {
+		return live[i].seq < live[j].seq
+	})
+
+	res := make([]Prospect, len(live))
+	for i, e := range live {
+		res[i] = e.Prospect
+	}
+
+	return res
+}
+
+// Merge dedupes the prospects of several searches fetched at the same time.
+func Merge(bySearch map[int][]prospety.Prospect) []Prospect {
+	ids := make([]int, 0, len(bySearch))
+	for id := range bySearch {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	s := New()
+	for _, id := range ids {
+		s.Add(Source{SearchID: id}, bySearch[id])
+	}
+
+	return s.Prospects()
+}
+
+// Collect fetches the prospects of every search and merges them.
+func Collect(c *prospety.Client, searchIDs ...int) ([]Prospect, error) {
+	s := New()
+	for _, id := range searchIDs {
+		prospects, err := c.GetProspects(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect prospects of search %d: %w", id, err)
+		}
+
+		s.Add(Source{SearchID: id, Time: time.Now()}, prospects)
+	}
+
+	return s.Prospects(), nil
+}
+
+// merge combines two records of the same channel into a new entry.
+func merge(a, b *entry) *entry {
+	older, newer := a, b
+	if b.Seen.Before(a.Seen) || (b.Seen.Equal(a.Seen) && b.seq < a.seq) {
+		older, newer = b, a
+	}
+
+	res := &entry{
+		Prospect: newer.Prospect,
+		seq:      min(a.seq, b.seq),
+	}
+
+	p := &res.Prospect.Prospect
+	o := &older.Prospect.Prospect
+	fillEmpty(p, o)
+
+	res.Links = dedupeLinks(older.Links, newer.Links)
+	res.Emails = unionStrings(older.Emails, newer.Emails)
+	res.SearchIDs = unionInts(older.SearchIDs, newer.SearchIDs)
+	res.keys = append(append([]string{}, older.keys...), newer.keys...)
+	res.Extra = mergeExtra(o.Extra, newer.Extra)
+
+	return res
+}
+
+// fillEmpty copies the fields p is missing from the older record o.
+func fillEmpty(p, o *prospety.Prospect) {
+	str := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	strs := func(dst *[]string, src []string) {
+		if len(*dst) == 0 {
+			*dst = src
+		}
+	}
+
+	str(&p.Photo, o.Photo)
+	str(&p.Name, o.Name)
+	str(&p.URL, o.URL)
+	str(&p.YouTubeChannelID, o.YouTubeChannelID)
+	str(&p.Description, o.Description)
+	str(&p.Phone, o.Phone)
+	str(&p.Category, o.Category)
+	str(&p.Country, o.Country)
+	strs(&p.Keywords, o.Keywords)
+	strs(&p.VideoKeywords, o.VideoKeywords)
+
+	if p.Email == "" {
+		p.Email = o.Email
+		p.EmailStatus = o.EmailStatus
+	}
+
+	if p.ChannelID == 0 {
+		p.ChannelID = o.ChannelID
+	}
+
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = o.CreatedAt
+	}
+
+	if p.LastVideo.Before(o.LastVideo.Time) {
+		p.LastVideo = o.LastVideo
+	}
+}
+
+func dedupeLinks(a, b []string) []string {
+	var res []string
+	seen := make(map[string]bool)
+	for _, links := range [][]string{a, b} {
+		for _, l := range links {
+			k := comparableLink(l)
+			if k == "" || seen[k] {
+				continue
+			}
+
+			seen[k] = true
+			res = append(res, strings.TrimSpace(l))
+		}
+	}
+
+	return res
+}
+
+func comparableLink(l string) string {
+	l = strings.ToLower(strings.TrimSpace(l))
+	l = strings.TrimPrefix(l, "https://")
+	l = strings.TrimPrefix(l, "http://")
+	l = strings.TrimPrefix(l, "www.")
+
+	return strings.TrimSuffix(l, "/")
+}
+
+// unionStrings joins a and b, ignoring case when comparing.
+func unionStrings(a, b []string) []string {
+	var res []string
+	seen := make(map[string]bool)
+	for _, s := range append(append([]string{}, a...), b...) {
+		k := strings.ToLower(s)
+		if seen[k] {
+			continue
+		}
+
+		seen[k] = true
+		res = append(res, s)
+	}
+
+	return res
+}
+
+func unionInts(a, b []int) []int {
+	var res []int
+	seen := make(map[int]bool)
+	for _, n := range append(append([]int{}, a...), b...) {
+		if seen[n] {
+			continue
+		}
+
+		seen[n] = true
+		res = append(res, n)
+	}
+	sort.Ints(res)
+
+	return res
+}
+
+func mergeExtra(older, newer map[string]json.RawMessage) map[string]json.RawMessage {
+	if len(older) == 0 {
+		return newer
+	}
+
+	res := make(map[string]json.RawMessage, len(older)+len(newer))
+	for k, v := range older {
+		res[k] = v
+	}
+	for k, v := range newer {
+		res[k] = v
+	}
+
+	return res
+}
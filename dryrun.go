@@ -0,0 +1,53 @@
+package prospety
+
+import (
+	"encoding/json"
+	"path"
+	"sync"
+)
+
+// DryRunRequest is a mutating request that a dry-run client did not send.
+type DryRunRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	requests []DryRunRequest
+}
+
+// _dryRunResponse is returned in place of the response body of a skipped
+// request. None of the mutating endpoints return anything we decode.
+var _dryRunResponse = []byte("{}")
+
+func (r *dryRunRecorder) record(method string, p []string, body []byte) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, DryRunRequest{
+		Method: method,
+		Path:   path.Join(p...),
+		Body:   body,
+	})
+
+	return _dryRunResponse
+}
+
+// DryRunRequests returns the requests recorded by a client created with
+// WithDryRun, oldest first.
+func (c *Client) DryRunRequests() []DryRunRequest {
+	r := c.options.dryRun
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]DryRunRequest, len(r.requests))
+	copy(res, r.requests)
+
+	return res
+}
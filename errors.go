@@ -0,0 +1,17 @@
+package prospety
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned when the API responds with a non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("request failed with status code %d", e.StatusCode)
+}
@@ -0,0 +1,136 @@
+package filter
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+// Type is the type of a field as far as filters are concerned.
+type Type int
+
+const (
+	String Type = iota + 1
+	Number
+	List
+	Timestamp
+)
+
+func (t Type) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	case List:
+		return "list"
+	case Timestamp:
+		return "time"
+	default:
+		return "unknown"
+	}
+}
+
+type field struct {
+	name string
+	typ  Type
+
+	str  func(p *prospety.Prospect) string
+	num  func(p *prospety.Prospect) float64
+	list func(p *prospety.Prospect) []string
+	time func(p *prospety.Prospect) time.Time
+}
+
+var (
+	_stringType = reflect.TypeOf("")
+	_listType   = reflect.TypeOf([]string{})
+	_timeType   = reflect.TypeOf(prospety.Time{})
+)
+
+// fields are the fields of prospety.Prospect by JSON name, plus derived
+// ones.
+var fields = sync.OnceValue(func() map[string]*field {
+	res := make(map[string]*field)
+	addFields(res, reflect.TypeOf(prospety.Prospect{}), nil)
+
+	res["views_per_video"] = &field{
+		name: "views_per_video",
+		typ:  Number,
+		num: func(p *prospety.Prospect) float64 {
+			if p.TotalVideos == 0 {
+				return 0
+			}
+			return float64(p.TotalViews) / float64(p.TotalVideos)
+		},
+	}
+
+	return res
+})
+
+func addFields(res map[string]*field, t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		idx := append(append([]int{}, index...), i)
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addFields(res, f.Type, idx)
+			continue
+		}
+
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+
+		get := func(p *prospety.Prospect) reflect.Value {
+			return reflect.ValueOf(p).Elem().FieldByIndex(idx)
+		}
+
+		fd := &field{name: name}
+		switch {
+		case f.Type == _stringType:
+			fd.typ = String
+			fd.str = func(p *prospety.Prospect) string { return get(p).String() }
+		case f.Type == _listType:
+			fd.typ = List
+			fd.list = func(p *prospety.Prospect) []string { return get(p).Interface().([]string) }
+		case f.Type == _timeType:
+			fd.typ = Timestamp
+			fd.time = func(p *prospety.Prospect) time.Time { return get(p).Interface().(prospety.Time).Time }
+		case f.Type.Kind() >= reflect.Int && f.Type.Kind() <= reflect.Int64:
+			fd.typ = Number
+			fd.num = func(p *prospety.Prospect) float64 { return float64(get(p).Int()) }
+		case f.Type.Kind() == reflect.Float32 || f.Type.Kind() == reflect.Float64:
+			fd.typ = Number
+			fd.num = func(p *prospety.Prospect) float64 { return get(p).Float() }
+		default:
+			continue
+		}
+
+		res[name] = fd
+	}
+}
+
+// Fields returns the names and types of the fields filters can refer to.
+func Fields() map[string]Type {
+	res := make(map[string]Type)
+	for name, f := range fields() {
+		res[name] = f.typ
+	}
+
+	return res
+}
+
+func fieldNames() []string {
+	var names []string
+	for name := range fields() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,108 @@
+// Package filter evaluates filter expressions over prospects locally, for
+// conditions the API's search criteria cannot express.
+//
+//	subscribers > 50k and country in ("US", "GB") and email != "" and last_video within 30d
+//
+// Fields are the JSON names of prospety.Prospect, plus views_per_video.
+// Expressions combine comparisons with and, or, not and parentheses:
+//
+//   - strings: ==, !=, in (…), not in (…), contains; all ignore case
+//   - numbers: ==, !=, <, <=, >, >=, in (…), not in (…); numbers take the
+//     suffixes k, m and b, as in 50k or 1.5m
+//   - lists: contains, true when any element contains the string
+//   - times: within a duration such as 30d (s, min, h, d, w, mo, y), or
+//     compared with a time string such as "2024-01-31"; == "" and != ""
+//     test whether the time is known
+package filter
+
+import (
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+// Filter is a parsed filter expression.
+type Filter struct {
+	src  string
+	pred predicate
+}
+
+// Parse parses src and checks it against the fields of prospety.Prospect.
+// Errors are *SyntaxError. Time strings such as "3 days ago" are resolved
+// at parse time.
+func Parse(src string) (*Filter, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := &parser{
+		tokens: tokens,
+		now:    time.Now(),
+	}
+
+	pred, err := ps.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := ps.peek(); t.kind != tokenEOF {
+		return nil, errorf(t.pos, "unexpected %s", describe(t))
+	}
+
+	return &Filter{
+		src:  src,
+		pred: pred,
+	}, nil
+}
+
+func MustParse(src string) *Filter {
+	f, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+func (f *Filter) String() string {
+	return f.src
+}
+
+// Match reports whether p matches, measuring "within" from now.
+func (f *Filter) Match(p *prospety.Prospect) bool {
+	return f.MatchAt(p, time.Now())
+}
+
+// MatchAt reports whether p matches, measuring "within" from now.
+func (f *Filter) MatchAt(p *prospety.Prospect, now time.Time) bool {
+	return f.pred(p, now)
+}
+
+// Apply returns the prospects that match, in order.
+func (f *Filter) Apply(prospects []prospety.Prospect) []prospety.Prospect {
+	now := time.Now()
+
+	var res []prospety.Prospect
+	for i := range prospects {
+		if f.pred(&prospects[i], now) {
+			res = append(res, prospects[i])
+		}
+	}
+
+	return res
+}
+
+// Seq filters a sequence of prospects. It has the shape of iter.Seq, so
+// the result can be ranged over.
+func (f *Filter) Seq(seq func(yield func(prospety.Prospect) bool)) func(yield func(prospety.Prospect) bool) {
+	return func(yield func(prospety.Prospect) bool) {
+		now := time.Now()
+		seq(func(p prospety.Prospect) bool {
+			if !f.pred(&p, now) {
+				return true
+			}
+			return yield(p)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of filter"
+	case tokenIdent:
+		return "name"
+	case tokenNumber:
+		return "number"
+	case tokenDuration:
+		return "duration"
+	case tokenString:
+		return "string"
+	case tokenOp:
+		return "operator"
+	case tokenLParen:
+		return `"("`
+	case tokenRParen:
+		return `")"`
+	case tokenComma:
+		return `","`
+	default:
+		return fmt.Sprintf("tokenKind(%d)", int(k))
+	}
+}
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string
+
+	num float64
+	dur time.Duration
+	str string
+}
+
+// SyntaxError is a filter that could not be parsed or does not fit the
+// fields it refers to.
+type SyntaxError struct {
+	// Pos is the byte offset in the filter the error refers to.
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: at %d: %s", e.Pos, e.Msg)
+}
+
+func errorf(pos int, format string, args ...any) error {
+	return &SyntaxError{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, args...),
+	}
+}
+
+// _numberSuffixes multiply numbers, so 50k is 50000.
+var _numberSuffixes = map[string]float64{
+	"k": 1e3,
+	"m": 1e6,
+	"b": 1e9,
+}
+
+// _durationUnits are the units of durations such as 30d. Months and years
+// are 30 and 365 days.
+var _durationUnits = map[string]time.Duration{
+	"s":   time.Second,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"d":   24 * time.Hour,
+	"w":   7 * 24 * time.Hour,
+	"mo":  30 * 24 * time.Hour,
+	"y":   365 * 24 * time.Hour,
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for {
+		for i < len(src) && unicode.IsSpace(rune(src[i])) {
+			i++
+		}
+
+		if i == len(src) {
+			return append(tokens, token{kind: tokenEOF, pos: i}), nil
+		}
+
+		start := i
+		c := src[i]
+		switch {
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: i, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: i, text: ")"})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, pos: i, text: ","})
+			i++
+		case c == '"' || c == '\'':
+			t, n, err := lexString(src[i:], i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i += n
+		case strings.ContainsRune("=!<>", rune(c)):
+			op := string(c)
+			if i+1 < len(src) && src[i+1] == '=' {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, errorf(i, "unknown operator %q", op)
+			}
+			tokens = append(tokens, token{kind: tokenOp, pos: i, text: op})
+			i += len(op)
+		case c >= '0' && c <= '9' || c == '.':
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			digits := strings.ReplaceAll(src[start:i], "_", "")
+
+			end := i
+			for i < len(src) && isLetter(src[i]) {
+				i++
+			}
+			suffix := strings.ToLower(src[end:i])
+
+			t, err := number(digits, suffix, start)
+			if err != nil {
+				return nil, err
+			}
+			t.text = src[start:i]
+			tokens = append(tokens, t)
+		case isLetter(c):
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, pos: start, text: src[start:i]})
+		default:
+			return nil, errorf(i, "unexpected %q", c)
+		}
+	}
+}
+
+func number(digits, suffix string, pos int) (token, error) {
+	n, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return token{}, errorf(pos, "invalid number %q", digits+suffix)
+	}
+
+	if suffix == "" {
+		return token{kind: tokenNumber, pos: pos, num: n}, nil
+	}
+
+	if mul, ok := _numberSuffixes[suffix]; ok {
+		return token{kind: tokenNumber, pos: pos, num: n * mul}, nil
+	}
+
+	if unit, ok := _durationUnits[suffix]; ok {
+		return token{kind: tokenDuration, pos: pos, dur: time.Duration(n * float64(unit))}, nil
+	}
+
+	return token{}, errorf(pos, "unknown suffix %q in %q", suffix, digits+suffix)
+}
+
+func lexString(src string, pos int) (token, int, error) {
+	quote := src[0]
+	var b strings.Builder
+	for i := 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == quote:
+			return token{kind: tokenString, pos: pos, text: src[:i+1], str: b.String()}, i + 1, nil
+		case c == '\\' && i+1 < len(src):
+			i++
+			b.WriteByte(src[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return token{}, 0, errorf(pos, "unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
@@ -0,0 +1,388 @@
+package filter
+
+import (
+	"strings"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+// predicate reports whether p matches, with now as the reference time for
+// "within".
+type predicate func(p *prospety.Prospect, now time.Time) bool
+
+type parser struct {
+	tokens []token
+	i      int
+	now    time.Time
+}
+
+func (ps *parser) peek() token {
+	return ps.tokens[ps.i]
+}
+
+func (ps *parser) next() token {
+	t := ps.tokens[ps.i]
+	if t.kind != tokenEOF {
+		ps.i++
+	}
+
+	return t
+}
+
+// keyword reports whether the next token is the keyword kw, and consumes
+// it if so.
+func (ps *parser) keyword(kw string) bool {
+	t := ps.peek()
+	if t.kind == tokenIdent && strings.EqualFold(t.text, kw) {
+		ps.i++
+		return true
+	}
+
+	return false
+}
+
+func (ps *parser) expect(kind tokenKind) (token, error) {
+	t := ps.next()
+	if t.kind != kind {
+		return t, errorf(t.pos, "expected %s, got %s", kind, describe(t))
+	}
+
+	return t, nil
+}
+
+func describe(t token) string {
+	if t.kind == tokenEOF {
+		return t.kind.String()
+	}
+
+	return "\"" + t.text + "\""
+}
+
+func (ps *parser) parseOr() (predicate, error) {
+	left, err := ps.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for ps.keyword("or") {
+		right, err := ps.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(p *prospety.Prospect, now time.Time) bool {
+			return l(p, now) || right(p, now)
+		}
+	}
+
+	return left, nil
+}
+
+func (ps *parser) parseAnd() (predicate, error) {
+	left, err := ps.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for ps.keyword("and") {
+		right, err := ps.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(p *prospety.Prospect, now time.Time) bool {
+			return l(p, now) && right(p, now)
+		}
+	}
+
+	return left, nil
+}
+
+func (ps *parser) parseNot() (predicate, error) {
+	if ps.keyword("not") {
+		inner, err := ps.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(p *prospety.Prospect, now time.Time) bool {
+			return !inner(p, now)
+		}, nil
+	}
+
+	if ps.peek().kind == tokenLParen {
+		ps.next()
+		inner, err := ps.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = ps.expect(tokenRParen)
+		if err != nil {
+			return nil, err
+		}
+
+		return inner, nil
+	}
+
+	return ps.parseComparison()
+}
+
+func (ps *parser) parseComparison() (predicate, error) {
+	name, err := ps.expect(tokenIdent)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := fields()[strings.ToLower(name.text)]
+	if !ok {
+		return nil, errorf(name.pos, "unknown field %q; known fields are %s", name.text, strings.Join(fieldNames(), ", "))
+	}
+
+	opTok := ps.peek()
+	var op string
+	switch {
+	case opTok.kind == tokenOp:
+		ps.next()
+		op = opTok.text
+	case ps.keyword("in"):
+		op = "in"
+	case ps.keyword("contains"):
+		op = "contains"
+	case ps.keyword("within"):
+		op = "within"
+	case ps.keyword("not"):
+		if !ps.keyword("in") {
+			return nil, errorf(ps.peek().pos, `expected "in" after "not"`)
+		}
+		op = "not in"
+	default:
+		return nil, errorf(opTok.pos, "expected operator after %s, got %s", f.name, describe(opTok))
+	}
+
+	var pred predicate
+	switch f.typ {
+	case String:
+		pred, err = ps.compareString(f, op, opTok.pos)
+	case Number:
+		pred, err = ps.compareNumber(f, op, opTok.pos)
+	case List:
+		pred, err = ps.compareList(f, op, opTok.pos)
+	case Timestamp:
+		pred, err = ps.compareTime(f, op, opTok.pos)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if op == "not in" {
+		in := pred
+		pred = func(p *prospety.Prospect, now time.Time) bool {
+			return !in(p, now)
+		}
+	}
+
+	return pred, nil
+}
+
+func unsupported(f *field, op string, pos int) error {
+	return errorf(pos, "operator %q does not apply to %s field %s", op, f.typ, f.name)
+}
+
+// literal reads a value of the given kind.
+func (ps *parser) literal(kind tokenKind, f *field) (token, error) {
+	t := ps.next()
+	if t.kind != kind {
+		return t, errorf(t.pos, "%s field %s needs a %s, got %s", f.typ, f.name, kind, describe(t))
+	}
+
+	return t, nil
+}
+
+// list reads a parenthesized, comma separated list of values of the given
+// kind.
+func (ps *parser) list(kind tokenKind, f *field) ([]token, error) {
+	_, err := ps.expect(tokenLParen)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []token
+	for {
+		t, err := ps.literal(kind, f)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+
+		sep := ps.next()
+		if sep.kind == tokenRParen {
+			return res, nil
+		}
+		if sep.kind != tokenComma {
+			return nil, errorf(sep.pos, `expected "," or ")", got %s`, describe(sep))
+		}
+	}
+}
+
+func (ps *parser) compareString(f *field, op string, pos int) (predicate, error) {
+	switch op {
+	case "==", "!=", "contains":
+		t, err := ps.literal(tokenString, f)
+		if err != nil {
+			return nil, err
+		}
+
+		want := t.str
+		switch op {
+		case "==":
+			return func(p *prospety.Prospect, _ time.Time) bool { return strings.EqualFold(f.str(p), want) }, nil
+		case "!=":
+			return func(p *prospety.Prospect, _ time.Time) bool { return !strings.EqualFold(f.str(p), want) }, nil
+		default:
+			want = strings.ToLower(want)
+			return func(p *prospety.Prospect, _ time.Time) bool {
+				return strings.Contains(strings.ToLower(f.str(p)), want)
+			}, nil
+		}
+	case "in", "not in":
+		ts, err := ps.list(tokenString, f)
+		if err != nil {
+			return nil, err
+		}
+
+		set := make(map[string]bool, len(ts))
+		for _, t := range ts {
+			set[strings.ToLower(t.str)] = true
+		}
+		return func(p *prospety.Prospect, _ time.Time) bool { return set[strings.ToLower(f.str(p))] }, nil
+	default:
+		return nil, unsupported(f, op, pos)
+	}
+}
+
+func (ps *parser) compareNumber(f *field, op string, pos int) (predicate, error) {
+	switch op {
+	case "in", "not in":
+		ts, err := ps.list(tokenNumber, f)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(p *prospety.Prospect, _ time.Time) bool {
+			v := f.num(p)
+			for _, t := range ts {
+				if v == t.num {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "contains", "within":
+		return nil, unsupported(f, op, pos)
+	}
+
+	t, err := ps.literal(tokenNumber, f)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := compare(op)
+	want := t.num
+	return func(p *prospety.Prospect, _ time.Time) bool {
+		v := f.num(p)
+		return cmp(v < want, v == want)
+	}, nil
+}
+
+func (ps *parser) compareList(f *field, op string, pos int) (predicate, error) {
+	if op != "contains" {
+		return nil, unsupported(f, op, pos)
+	}
+
+	t, err := ps.literal(tokenString, f)
+	if err != nil {
+		return nil, err
+	}
+
+	want := strings.ToLower(t.str)
+	return func(p *prospety.Prospect, _ time.Time) bool {
+		for _, s := range f.list(p) {
+			if strings.Contains(strings.ToLower(s), want) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (ps *parser) compareTime(f *field, op string, pos int) (predicate, error) {
+	switch op {
+	case "within":
+		t, err := ps.literal(tokenDuration, f)
+		if err != nil {
+			return nil, err
+		}
+
+		d := t.dur
+		return func(p *prospety.Prospect, now time.Time) bool {
+			v := f.time(p)
+			return !v.IsZero() && now.Sub(v) <= d
+		}, nil
+	case "in", "not in", "contains":
+		return nil, unsupported(f, op, pos)
+	}
+
+	t, err := ps.literal(tokenString, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// The empty string stands for an unknown time.
+	if t.str == "" {
+		switch op {
+		case "==":
+			return func(p *prospety.Prospect, _ time.Time) bool { return f.time(p).IsZero() }, nil
+		case "!=":
+			return func(p *prospety.Prospect, _ time.Time) bool { return !f.time(p).IsZero() }, nil
+		default:
+			return nil, errorf(t.pos, "cannot compare %s with an empty time", f.name)
+		}
+	}
+
+	want, err := prospety.ParseTime(t.str, ps.now)
+	if err != nil {
+		return nil, errorf(t.pos, "%s", err)
+	}
+
+	cmp := compare(op)
+	return func(p *prospety.Prospect, _ time.Time) bool {
+		v := f.time(p)
+		if v.IsZero() {
+			return false
+		}
+		return cmp(v.Before(want.Time), v.Equal(want.Time))
+	}, nil
+}
+
+// compare turns a comparison operator into a function of whether the
+// value is less than and equal to the operand.
+func compare(op string) func(less, equal bool) bool {
+	switch op {
+	case "==":
+		return func(less, equal bool) bool { return equal }
+	case "!=":
+		return func(less, equal bool) bool { return !equal }
+	case "<":
+		return func(less, equal bool) bool { return less }
+	case "<=":
+		return func(less, equal bool) bool { return less || equal }
+	case ">":
+		return func(less, equal bool) bool { return !less && !equal }
+	default: // ">="
+		return func(less, equal bool) bool { return !less }
+	}
+}
@@ -0,0 +1,199 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+var _now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testProspect() *prospety.Prospect {
+	p := &prospety.Prospect{
+		ChannelID:        prospety.ChannelYouTube,
+		YouTubeChannelID: "UC0123456789abcdefghijkl",
+		Country:          "US",
+		Keywords:         []string{"Minecraft", "speedrun"},
+		Subscribers:      60_000,
+		TotalViews:       1_000_000,
+		TotalVideos:      100,
+		Engagement:       0.042,
+		LastVideo:        prospety.Time{Time: _now.Add(-10 * 24 * time.Hour)},
+	}
+	p.Name = "Foo Gaming"
+
+	return p
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		// and binds tighter than or, not tighter than and.
+		{`country == "US" or subscribers > 100k and email != ""`, true},
+		{`(country == "US" or subscribers > 100k) and email != ""`, false},
+		{`not country == "GB" and subscribers < 10k`, false},
+		{`not (country == "GB" and subscribers < 10k)`, true},
+		{`not not country == "US"`, true},
+		{`country == "GB" or country == "CA" or country == "US"`, true},
+		{`country == "US" and subscribers > 50k and email == ""`, true},
+
+		// Keywords and strings ignore case.
+		{`COUNTRY IN ("gb", "us") AND Subscribers >= 60k`, true},
+		{`country not in ("US", "GB")`, false},
+		{`name contains "gam"`, true},
+		{`name == "foo gaming"`, true},
+		{`name != "foo gaming"`, false},
+		{`keywords contains "SPEED"`, true},
+		{`keywords contains "fortnite"`, false},
+
+		// Numbers.
+		{`subscribers == 60k`, true},
+		{`subscribers > 5_000`, true},
+		{`subscribers >= 1.5m`, false},
+		{`subscribers in (1, 60000)`, true},
+		{`subscribers not in (1, 2)`, true},
+		{`engagement < 0.05`, true},
+		{`views_per_video == 10k`, true},
+
+		// Times, measured from _now.
+		{`last_video within 30d`, true},
+		{`last_video within 1w`, false},
+		{`last_video > "2024-01-01"`, true},
+		{`last_video <= "2024-05-01"`, false},
+		{`last_video != ""`, true},
+		{`created_at == ""`, true},
+		{`created_at > "2000-01-01"`, false},
+	}
+
+	p := testProspect()
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			f, err := Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := f.MatchAt(p, _now); got != tt.want {
+				t.Errorf("MatchAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+		msg string
+	}{
+		{``, 0, `expected name, got end of filter`},
+		{`subscribers > 50k and`, 21, `expected name, got end of filter`},
+		{`followers > 10`, 0, `unknown field "followers"`},
+		{`subscribers = 1`, 12, `unknown operator "="`},
+		{`subscribers ! 1`, 12, `unknown operator "!"`},
+		{`subscribers 10`, 12, `expected operator after subscribers, got "10"`},
+		{`subscribers > 10x`, 14, `unknown suffix "x"`},
+		{`subscribers > 1.2.3`, 14, `invalid number`},
+		{`subscribers contains "x"`, 12, `operator "contains" does not apply to number field subscribers`},
+		{`country == 5`, 11, `string field country needs a string, got "5"`},
+		{`country < "US"`, 8, `operator "<" does not apply to string field country`},
+		{`country not "US"`, 12, `expected "in" after "not"`},
+		{`country in "US"`, 11, `expected "(", got ""US""`},
+		{`country in ("US" "GB")`, 17, `expected "," or ")"`},
+		{`keywords == "x"`, 9, `operator "==" does not apply to list field keywords`},
+		{`last_video within 30`, 18, `needs a duration, got "30"`},
+		{`last_video < ""`, 13, `cannot compare last_video with an empty time`},
+		{`(country == "US"`, 16, `expected ")", got end of filter`},
+		{`country == "US")`, 15, `unexpected ")"`},
+		{`country == "US" country`, 16, `unexpected "country"`},
+		{`name == "abc`, 8, `unterminated string`},
+		{`name == "a" & x`, 12, `unexpected '&'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Parse error = %v, want a *SyntaxError", err)
+			}
+
+			if se.Pos != tt.pos || !strings.Contains(se.Msg, tt.msg) {
+				t.Errorf("Parse error at %d: %q, want at %d: %q", se.Pos, se.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	p := testProspect()
+	p.Email = "foo@example.com"
+
+	tests := []struct {
+		name string
+		typ  Type
+		want any
+	}{
+		{"name", String, "Foo Gaming"},
+		{"email", String, "foo@example.com"},
+		{"channel_id", Number, float64(prospety.ChannelYouTube)},
+		{"youtube_channel_id", String, "UC0123456789abcdefghijkl"},
+		{"keywords", List, []string{"Minecraft", "speedrun"}},
+		{"subscribers", Number, 60_000.0},
+		{"total_videos", Number, 100.0},
+		{"engagement", Number, 0.042},
+		{"views_per_video", Number, 10_000.0},
+		{"last_video", Timestamp, _now.Add(-10 * 24 * time.Hour)},
+	}
+
+	all := Fields()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if all[tt.name] != tt.typ {
+				t.Fatalf("Fields()[%q] = %s, want %s", tt.name, all[tt.name], tt.typ)
+			}
+
+			f := fields()[tt.name]
+			var got any
+			switch f.typ {
+			case String:
+				got = f.str(p)
+			case Number:
+				got = f.num(p)
+			case List:
+				got = f.list(p)
+			case Timestamp:
+				got = f.time(p)
+			}
+
+			if gotList, ok := got.([]string); ok {
+				if strings.Join(gotList, ",") != strings.Join(tt.want.([]string), ",") {
+					t.Errorf("value = %q, want %q", got, tt.want)
+				}
+				return
+			}
+			if gotTime, ok := got.(time.Time); ok {
+				if !gotTime.Equal(tt.want.(time.Time)) {
+					t.Errorf("value = %s, want %s", got, tt.want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("value = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Fields the API returns but Prospect does not know are not filterable.
+	for _, name := range []string{"extra", "Extra", "-"} {
+		if _, ok := all[name]; ok {
+			t.Errorf("Fields() has %q", name)
+		}
+	}
+}
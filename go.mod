@@ -1,10 +1,21 @@
 module github.com/bjornpagen/prospety-go
 
-go 1.20
+go 1.21
 
-require go.uber.org/ratelimit v0.2.0
+require (
+	github.com/andres-erbsen/clock v0.0.0-20160526145045-9e14626cd129
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/metric v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/sdk/metric v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+	go.uber.org/ratelimit v0.2.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/andres-erbsen/clock v0.0.0-20160526145045-9e14626cd129 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
 )
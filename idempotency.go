@@ -0,0 +1,182 @@
+package prospety
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const _idempotencyHeader = "Idempotency-Key"
+
+// _idempotentOperations are the operations that are sent with an
+// idempotency key.
+var _idempotentOperations = map[string]bool{
+	"CreateSearch":      true,
+	"CreateQuickSearch": true,
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey sets the key sent with creations made through
+// Client.WithContext(ctx). Retrying a creation with the same key lets the
+// server recognise it as a duplicate.
+//
+// Calls such as Apply and BulkQuickSearch make many creations with one
+// context, so key is not sent as is: every creation gets key followed by a
+// hash of its method, path and body. Different creations therefore get
+// different keys, and only a creation repeated with the same key and the
+// same request is a duplicate.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// WithIdempotencyKeys generates a key for every creation that has none set
+// with WithIdempotencyKey, so at least the client's own retries are safe.
+func WithIdempotencyKeys() Option {
+	return func(option *options) error {
+		option.autoIdempotencyKeys = true
+		return nil
+	}
+}
+
+// WithDuplicateWindow makes CreateSearch and CreateQuickSearch check the
+// account for an identical creation within window before creating another,
+// for when the server does not honor idempotency keys. A duplicate is
+// reported as success without creating anything.
+//
+// Searches are identical when title, limit and data match. Quick searches
+// are identical when the prospect URL matches, which is only known once
+// the earlier quick search has found its prospect.
+func WithDuplicateWindow(window time.Duration) Option {
+	return func(option *options) error {
+		if window < 0 {
+			return fmt.Errorf("duplicate window must not be negative: %s", window)
+		}
+
+		option.duplicateWindow = window
+		return nil
+	}
+}
+
+func (c *Client) idempotencyKey(ctx context.Context, op string, req *http.Request) (string, error) {
+	if !_idempotentOperations[op] {
+		return "", nil
+	}
+
+	if key, ok := ctx.Value(idempotencyKey{}).(string); ok && key != "" {
+		return requestKey(key, req)
+	}
+
+	if !c.options.autoIdempotencyKeys {
+		return "", nil
+	}
+
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// requestKey derives the key of a single creation from the key set on its
+// context.
+func requestKey(key string, req *http.Request) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.RequestURI())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		defer body.Close()
+
+		_, err = io.Copy(h, body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	return key + "-" + hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+func (c *Client) recent(created time.Time) bool {
+	return c.options.clock.Now().Sub(created) <= c.options.duplicateWindow
+}
+
+// duplicateSearch reports whether an identical search was created within
+// the duplicate window.
+func (c *Client) duplicateSearch(title string, limit int, data StandardSearch) (bool, error) {
+	if c.options.duplicateWindow == 0 {
+		return false, nil
+	}
+
+	searches, err := c.GetSearches()
+	if err != nil {
+		return false, fmt.Errorf("failed to check for duplicate search: %w", err)
+	}
+
+	want := flattenData(data)
+	for _, s := range searches {
+		if s.Title != title || s.Limit != limit {
+			continue
+		}
+
+		if s.CreatedAt.IsZero() || !c.recent(s.CreatedAt.Time) {
+			continue
+		}
+
+		if reflect.DeepEqual(flattenData(s.Data), want) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// duplicateQuickSearch returns the quick search for url in idx created
+// within the duplicate window, if any.
+func (c *Client) duplicateQuickSearch(idx *QuickSearchIndex, url string) *QuickSearch {
+	if c.options.duplicateWindow == 0 {
+		return nil
+	}
+
+	for _, qs := range idx.Lookup(url) {
+		if !qs.CreatedAt.IsZero() && c.recent(qs.CreatedAt.Time) {
+			return &qs
+		}
+	}
+
+	return nil
+}
+
+// channelKey identifies the channel of u, falling back to comparableURL
+// for URLs ParseChannelURL does not understand.
+func channelKey(u string) string {
+	if parsed, err := ParseChannelURL(u); err == nil {
+		return parsed.Key()
+	}
+
+	return comparableURL(u)
+}
+
+// comparableURL strips the differences between URLs that point to the same
+// channel but were typed differently.
+func comparableURL(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	u = strings.TrimPrefix(u, "https://")
+	u = strings.TrimPrefix(u, "http://")
+	u = strings.TrimPrefix(u, "www.")
+	u = strings.TrimPrefix(u, "m.")
+
+	return strings.TrimSuffix(u, "/")
+}
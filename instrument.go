@@ -0,0 +1,47 @@
+package prospety
+
+import (
+	"context"
+	"time"
+)
+
+// Instrumentation observes every API call made by a Client. See the
+// prospety/otel package for an OpenTelemetry implementation.
+type Instrumentation interface {
+	// StartCall is called before the call waits on the rate limiter. The
+	// returned context is used for the HTTP request.
+	StartCall(ctx context.Context, op Operation) (context.Context, CallObserver)
+}
+
+type CallObserver interface {
+	// RateLimitWait reports how long the call waited on the rate limiter.
+	RateLimitWait(d time.Duration)
+
+	// Retry is called before every attempt after the first one.
+	Retry(attempt int)
+
+	// End is called once with the final status code, which is 0 when no
+	// response was received.
+	End(statusCode int, err error)
+}
+
+func WithInstrumentation(i Instrumentation) Option {
+	return func(option *options) error {
+		option.instrumentation = i
+		return nil
+	}
+}
+
+type nopObserver struct{}
+
+func (nopObserver) RateLimitWait(time.Duration) {}
+func (nopObserver) Retry(int)                   {}
+func (nopObserver) End(int, error)              {}
+
+func (c *Client) startCall(ctx context.Context, op Operation) (context.Context, CallObserver) {
+	if c.options.instrumentation == nil {
+		return ctx, nopObserver{}
+	}
+
+	return c.options.instrumentation.StartCall(ctx, op)
+}
@@ -0,0 +1,32 @@
+// Package safefile writes files that readers never see half written.
+package safefile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write writes data to path through a temporary file in the same directory,
+// which is renamed into place once complete. On error the temporary file is
+// removed and path is left as it was.
+func Write(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package prospety
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestInfo describes a request about to be sent. Header and Body are
+// redacted copies and safe to log.
+type RequestInfo struct {
+	Method  string
+	Path    string
+	Header  http.Header
+	Body    []byte
+	Attempt int
+}
+
+// ResponseInfo describes the outcome of a request. Err is set when no
+// response was received.
+type ResponseInfo struct {
+	Request    *RequestInfo
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Latency    time.Duration
+	Err        error
+}
+
+type RequestHook func(info *RequestInfo)
+
+type ResponseHook func(info *ResponseInfo)
+
+func WithRequestHook(h RequestHook) Option {
+	return func(option *options) error {
+		option.requestHooks = append(option.requestHooks, h)
+		return nil
+	}
+}
+
+func WithResponseHook(h ResponseHook) Option {
+	return func(option *options) error {
+		option.responseHooks = append(option.responseHooks, h)
+		return nil
+	}
+}
+
+// WithLogger logs every request at debug level, and failed requests at warn
+// level. Bodies are only logged together with WithLogBodies.
+func WithLogger(l *slog.Logger) Option {
+	return func(option *options) error {
+		option.logger = l
+		return nil
+	}
+}
+
+// WithLogBodies adds redacted request and response bodies to the log.
+func WithLogBodies() Option {
+	return func(option *options) error {
+		option.logBodies = true
+		return nil
+	}
+}
+
+func (o *options) observed() bool {
+	return o.logger != nil || len(o.requestHooks) > 0 || len(o.responseHooks) > 0
+}
+
+func (c *Client) onRequest(req *http.Request, attempt int) *RequestInfo {
+	if !c.options.observed() {
+		return nil
+	}
+
+	info := &RequestInfo{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Header:  redactHeader(req.Header),
+		Attempt: attempt,
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			data, _ := io.ReadAll(body)
+			info.Body = redactBody(data)
+		}
+	}
+
+	for _, h := range c.options.requestHooks {
+		h(info)
+	}
+
+	return info
+}
+
+func (c *Client) onResponse(req *RequestInfo, resp *http.Response, body []byte, latency time.Duration, err error) {
+	if req == nil {
+		return
+	}
+
+	info := &ResponseInfo{
+		Request: req,
+		Latency: latency,
+		Err:     err,
+	}
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+		info.Header = redactHeader(resp.Header)
+		info.Body = redactBody(body)
+	}
+
+	for _, h := range c.options.responseHooks {
+		h(info)
+	}
+
+	if c.options.logger != nil {
+		c.log(info)
+	}
+}
+
+func (c *Client) log(info *ResponseInfo) {
+	level := slog.LevelDebug
+	if info.Err != nil || info.StatusCode < 200 || info.StatusCode >= 300 {
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", info.Request.Method),
+		slog.String("path", info.Request.Path),
+		slog.Int("status", info.StatusCode),
+		slog.Duration("latency", info.Latency),
+		slog.Int("retries", info.Request.Attempt-1),
+	}
+	if info.Err != nil {
+		attrs = append(attrs, slog.String("error", info.Err.Error()))
+	}
+	if c.options.logBodies {
+		attrs = append(attrs,
+			slog.String("request_body", string(info.Request.Body)),
+			slog.String("response_body", string(info.Body)),
+		)
+	}
+
+	c.options.logger.LogAttrs(context.Background(), level, "prospety request", attrs...)
+}
+
+const _redacted = "[REDACTED]"
+
+func redactHeader(h http.Header) http.Header {
+	res := h.Clone()
+	if res.Get("Authorization") != "" {
+		res.Set("Authorization", "Bearer "+_redacted)
+	}
+
+	return res
+}
+
+var _piiKeys = map[string]bool{
+	"email":  true,
+	"emails": true,
+	"phone":  true,
+	"phones": true,
+}
+
+// redactBody masks prospect contact details in JSON bodies. Anything that
+// isn't JSON, such as CSV exports, is dropped entirely.
+func redactBody(data []byte) []byte {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var v any
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return []byte(_redacted)
+	}
+
+	res, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return []byte(_redacted)
+	}
+
+	return res
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if _piiKeys[strings.ToLower(k)] {
+				if val != nil && val != "" {
+					v[k] = _redacted
+				}
+				continue
+			}
+			v[k] = redactValue(val)
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = redactValue(val)
+		}
+	}
+
+	return v
+}
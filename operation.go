@@ -0,0 +1,109 @@
+package prospety
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Operation identifies the API call a request was made for.
+type Operation struct {
+	// Name is the Client method, e.g. "StartSearch".
+	Name   string
+	Method string
+	Path   string
+
+	// SearchID is set for calls about a single search.
+	SearchID int
+
+	// Page is set for paginated listings, 1 indexed like the API.
+	Page int
+
+	Class EndpointClass
+}
+
+type route struct {
+	method  string
+	pattern []string
+	name    string
+	class   EndpointClass
+}
+
+// _routes maps every endpoint the client uses to the method that calls it.
+// "{id}" matches a numeric path segment.
+var _routes = []route{
+	{"GET", []string{"channels"}, "GetChannels", ClassListing},
+	{"GET", []string{"channels", "{id}"}, "GetChannel", ClassListing},
+	{"GET", []string{"quick_searches"}, "GetQuickSearches", ClassListing},
+	{"POST", []string{"quick_searches"}, "CreateQuickSearch", ClassMutation},
+	{"GET", []string{"quick_searches", "{id}"}, "GetQuickSearch", ClassListing},
+	{"DELETE", []string{"quick_searches", "{id}"}, "DeleteQuickSearch", ClassMutation},
+	{"PUT", []string{"searches", "potential-prospects", "count"}, "GetPotentialProspectsCount", ClassPreview},
+	{"PUT", []string{"searches", "potential-prospects", "preview"}, "GetPotentialProspects", ClassPreview},
+	{"GET", []string{"searches"}, "GetSearches", ClassListing},
+	{"PUT", []string{"searches"}, "CreateSearch", ClassMutation},
+	{"GET", []string{"searches", "{id}"}, "GetSearch", ClassListing},
+	{"PUT", []string{"searches", "{id}"}, "UpdateSearch", ClassMutation},
+	{"DELETE", []string{"searches", "{id}"}, "DeleteSearch", ClassMutation},
+	{"PUT", []string{"searches", "{id}", "start"}, "StartSearch", ClassMutation},
+	{"PUT", []string{"searches", "{id}", "pause"}, "PauseSearch", ClassMutation},
+	{"PUT", []string{"searches", "{id}", "finish"}, "FinishSearch", ClassMutation},
+	{"GET", []string{"searches", "{id}", "prospects"}, "GetProspects", ClassListing},
+	{"GET", []string{"searches", "{id}", "prospects", "export"}, "ExportProspects", ClassExport},
+}
+
+func describe(method string, path []string, query url.Values) Operation {
+	op := Operation{
+		Method: method,
+		Path:   strings.Join(path, "/"),
+	}
+
+	if page, err := strconv.Atoi(query.Get("page")); err == nil {
+		op.Page = page
+	}
+
+	for _, r := range _routes {
+		id, ok := r.match(method, path)
+		if !ok {
+			continue
+		}
+
+		op.Name = r.name
+		op.Class = r.class
+		if r.pattern[0] == "searches" {
+			op.SearchID = id
+		}
+		return op
+	}
+
+	op.Name = method + " " + op.Path
+	op.Class = ClassMutation
+	if method == "GET" {
+		op.Class = ClassListing
+	}
+	return op
+}
+
+func (r *route) match(method string, path []string) (int, bool) {
+	if r.method != method || len(r.pattern) != len(path) {
+		return 0, false
+	}
+
+	var id int
+	for i, seg := range r.pattern {
+		if seg != "{id}" {
+			if seg != path[i] {
+				return 0, false
+			}
+			continue
+		}
+
+		n, err := strconv.Atoi(path[i])
+		if err != nil {
+			return 0, false
+		}
+		id = n
+	}
+
+	return id, true
+}
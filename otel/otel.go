@@ -0,0 +1,182 @@
+// Package otel instruments a prospety Client with OpenTelemetry traces and
+// metrics.
+//
+//	inst, err := otel.New()
+//	...
+//	c, err := prospety.New(apiKey, prospety.WithInstrumentation(inst))
+package otel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _scope = "github.com/bjornpagen/prospety-go/otel"
+
+const (
+	AttrOperation  = attribute.Key("prospety.operation")
+	AttrSearchID   = attribute.Key("prospety.search_id")
+	AttrPage       = attribute.Key("prospety.page")
+	AttrStatusCode = attribute.Key("http.response.status_code")
+	AttrMethod     = attribute.Key("http.request.method")
+)
+
+type Option func(option *options)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider defaults to the global tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(option *options) {
+		option.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider defaults to the global meter provider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(option *options) {
+		option.meterProvider = mp
+	}
+}
+
+// Instrumentation implements prospety.Instrumentation.
+type Instrumentation struct {
+	tracer trace.Tracer
+
+	requests      metric.Int64Counter
+	duration      metric.Float64Histogram
+	retries       metric.Int64Counter
+	rateLimitWait metric.Float64Histogram
+}
+
+var _ prospety.Instrumentation = (*Instrumentation)(nil)
+
+func New(opts ...Option) (*Instrumentation, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.tracerProvider == nil {
+		o.tracerProvider = otel.GetTracerProvider()
+	}
+
+	if o.meterProvider == nil {
+		o.meterProvider = otel.GetMeterProvider()
+	}
+
+	meter := o.meterProvider.Meter(_scope)
+	i := &Instrumentation{
+		tracer: o.tracerProvider.Tracer(_scope),
+	}
+
+	var err error
+	i.requests, err = meter.Int64Counter("prospety.client.requests",
+		metric.WithDescription("Number of Prospety API calls."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create requests counter: %w", err)
+	}
+
+	i.duration, err = meter.Float64Histogram("prospety.client.duration",
+		metric.WithDescription("Duration of Prospety API calls, including rate limiting and retries."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duration histogram: %w", err)
+	}
+
+	i.retries, err = meter.Int64Counter("prospety.client.retries",
+		metric.WithDescription("Number of retried Prospety API requests."),
+		metric.WithUnit("{retry}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retries counter: %w", err)
+	}
+
+	i.rateLimitWait, err = meter.Float64Histogram("prospety.client.rate_limit.wait",
+		metric.WithDescription("Time spent waiting on the client rate limiter."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limit wait histogram: %w", err)
+	}
+
+	return i, nil
+}
+
+func (i *Instrumentation) StartCall(ctx context.Context, op prospety.Operation) (context.Context, prospety.CallObserver) {
+	attrs := []attribute.KeyValue{
+		AttrOperation.String(op.Name),
+		AttrMethod.String(op.Method),
+	}
+	if op.SearchID != 0 {
+		attrs = append(attrs, AttrSearchID.Int(op.SearchID))
+	}
+	if op.Page != 0 {
+		attrs = append(attrs, AttrPage.Int(op.Page))
+	}
+
+	ctx, span := i.tracer.Start(ctx, "prospety."+op.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, &call{
+		ctx:   ctx,
+		inst:  i,
+		span:  span,
+		op:    op,
+		start: time.Now(),
+	}
+}
+
+type call struct {
+	ctx   context.Context
+	inst  *Instrumentation
+	span  trace.Span
+	op    prospety.Operation
+	start time.Time
+}
+
+// metricAttrs leaves out search IDs and pages to keep cardinality bounded.
+func (c *call) metricAttrs(extra ...attribute.KeyValue) metric.MeasurementOption {
+	attrs := append([]attribute.KeyValue{AttrOperation.String(c.op.Name)}, extra...)
+	return metric.WithAttributes(attrs...)
+}
+
+func (c *call) RateLimitWait(d time.Duration) {
+	c.inst.rateLimitWait.Record(c.ctx, d.Seconds(), c.metricAttrs())
+	if d > 0 {
+		c.span.AddEvent("rate limited", trace.WithAttributes(attribute.String("wait", d.String())))
+	}
+}
+
+func (c *call) Retry(attempt int) {
+	c.inst.retries.Add(c.ctx, 1, c.metricAttrs())
+	c.span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt)))
+}
+
+func (c *call) End(statusCode int, err error) {
+	attrs := []attribute.KeyValue{AttrStatusCode.Int(statusCode)}
+	c.span.SetAttributes(attrs...)
+
+	if err != nil {
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+	} else if statusCode >= http.StatusBadRequest {
+		c.span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+	c.span.End()
+
+	c.inst.requests.Add(c.ctx, 1, c.metricAttrs(attrs...))
+	c.inst.duration.Record(c.ctx, time.Since(c.start).Seconds(), c.metricAttrs(attrs...))
+}
@@ -0,0 +1,212 @@
+package otel_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+	prospetyotel "github.com/bjornpagen/prospety-go/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type harness struct {
+	inst   *prospetyotel.Instrumentation
+	spans  *tracetest.InMemoryExporter
+	reader *sdkmetric.ManualReader
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	h := &harness{
+		spans:  tracetest.NewInMemoryExporter(),
+		reader: sdkmetric.NewManualReader(),
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(h.spans))
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(h.reader))
+
+	var err error
+	h.inst, err = prospetyotel.New(
+		prospetyotel.WithTracerProvider(tp),
+		prospetyotel.WithMeterProvider(mp),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func (h *harness) metrics(t *testing.T) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	err := h.reader.Collect(context.Background(), &rm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			res[m.Name] = m.Data
+		}
+	}
+
+	return res
+}
+
+func wantAttr(t *testing.T, set attribute.Set, key attribute.Key, want attribute.Value) {
+	t.Helper()
+
+	got, ok := set.Value(key)
+	if !ok {
+		t.Errorf("missing attribute %s", key)
+		return
+	}
+	if got != want {
+		t.Errorf("attribute %s = %v, want %v", key, got.Emit(), want.Emit())
+	}
+}
+
+func TestSpan(t *testing.T) {
+	h := newHarness(t)
+
+	_, call := h.inst.StartCall(context.Background(), prospety.Operation{
+		Name:     "GetProspects",
+		Method:   http.MethodGet,
+		SearchID: 42,
+		Page:     3,
+	})
+	call.RateLimitWait(time.Second)
+	call.Retry(2)
+	call.End(http.StatusOK, nil)
+
+	spans := h.spans.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	s := spans[0]
+
+	if s.Name != "prospety.GetProspects" {
+		t.Errorf("span name = %q, want %q", s.Name, "prospety.GetProspects")
+	}
+	if s.SpanKind != trace.SpanKindClient {
+		t.Errorf("span kind = %s, want %s", s.SpanKind, trace.SpanKindClient)
+	}
+	if s.Status.Code != codes.Unset {
+		t.Errorf("span status = %s, want %s", s.Status.Code, codes.Unset)
+	}
+
+	attrs := attribute.NewSet(s.Attributes...)
+	wantAttr(t, attrs, prospetyotel.AttrOperation, attribute.StringValue("GetProspects"))
+	wantAttr(t, attrs, prospetyotel.AttrMethod, attribute.StringValue(http.MethodGet))
+	wantAttr(t, attrs, prospetyotel.AttrSearchID, attribute.IntValue(42))
+	wantAttr(t, attrs, prospetyotel.AttrPage, attribute.IntValue(3))
+	wantAttr(t, attrs, prospetyotel.AttrStatusCode, attribute.IntValue(http.StatusOK))
+
+	var events []string
+	for _, e := range s.Events {
+		events = append(events, e.Name)
+	}
+	if len(events) != 2 || events[0] != "rate limited" || events[1] != "retry" {
+		t.Errorf("span events = %q, want %q", events, []string{"rate limited", "retry"})
+	}
+}
+
+func TestSpanError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+	}{
+		{"transport error", 0, errors.New("connection refused")},
+		{"error status", http.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+
+			_, call := h.inst.StartCall(context.Background(), prospety.Operation{
+				Name:   "CreateSearch",
+				Method: http.MethodPost,
+			})
+			call.End(tt.status, tt.err)
+
+			s := h.spans.GetSpans()[0]
+			if s.Status.Code != codes.Error {
+				t.Errorf("span status = %s, want %s", s.Status.Code, codes.Error)
+			}
+
+			attrs := attribute.NewSet(s.Attributes...)
+			if _, ok := attrs.Value(prospetyotel.AttrSearchID); ok {
+				t.Errorf("span has %s without a search", prospetyotel.AttrSearchID)
+			}
+			wantAttr(t, attrs, prospetyotel.AttrStatusCode, attribute.IntValue(tt.status))
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	h := newHarness(t)
+
+	for i := 0; i < 2; i++ {
+		_, call := h.inst.StartCall(context.Background(), prospety.Operation{
+			Name:     "GetSearch",
+			Method:   http.MethodGet,
+			SearchID: i + 1,
+		})
+		call.RateLimitWait(250 * time.Millisecond)
+		call.Retry(2)
+		call.End(http.StatusOK, nil)
+	}
+
+	m := h.metrics(t)
+
+	requests, ok := m["prospety.client.requests"].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("prospety.client.requests is %T, want a sum", m["prospety.client.requests"])
+	}
+	// Search IDs are left out of metrics, so both calls share a series.
+	if len(requests.DataPoints) != 1 {
+		t.Fatalf("got %d request series, want 1", len(requests.DataPoints))
+	}
+	dp := requests.DataPoints[0]
+	if dp.Value != 2 {
+		t.Errorf("requests = %d, want 2", dp.Value)
+	}
+	wantAttr(t, dp.Attributes, prospetyotel.AttrOperation, attribute.StringValue("GetSearch"))
+	wantAttr(t, dp.Attributes, prospetyotel.AttrStatusCode, attribute.IntValue(http.StatusOK))
+	if _, ok := dp.Attributes.Value(prospetyotel.AttrSearchID); ok {
+		t.Errorf("request metric has %s", prospetyotel.AttrSearchID)
+	}
+
+	retries, ok := m["prospety.client.retries"].(metricdata.Sum[int64])
+	if !ok || len(retries.DataPoints) != 1 || retries.DataPoints[0].Value != 2 {
+		t.Errorf("retries = %+v, want a single series of 2", m["prospety.client.retries"])
+	}
+
+	wait, ok := m["prospety.client.rate_limit.wait"].(metricdata.Histogram[float64])
+	if !ok || len(wait.DataPoints) != 1 {
+		t.Fatalf("rate limit wait = %+v, want a single histogram series", m["prospety.client.rate_limit.wait"])
+	}
+	if wdp := wait.DataPoints[0]; wdp.Count != 2 || wdp.Sum != 0.5 {
+		t.Errorf("rate limit wait count %d, sum %g, want 2 and 0.5", wdp.Count, wdp.Sum)
+	}
+
+	duration, ok := m["prospety.client.duration"].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 || duration.DataPoints[0].Count != 2 {
+		t.Errorf("duration = %+v, want a single histogram series of 2 calls", m["prospety.client.duration"])
+	}
+}
@@ -0,0 +1,142 @@
+package prospety
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const _defaultPageConcurrency = 4
+
+// WithPageConcurrency sets how many pages of a listing are fetched at once
+// once the first page has revealed the total. The default is 4; 1 fetches
+// pages sequentially.
+func WithPageConcurrency(n int) Option {
+	return func(option *options) error {
+		if n < 1 {
+			return fmt.Errorf("page concurrency must be at least 1: %d", n)
+		}
+
+		option.pageConcurrency = n
+		return nil
+	}
+}
+
+// pageFetcher fetches a single page, 0 indexed, and returns the total
+// number of items reported by the API along with the page.
+type pageFetcher[T any] func(c *Client, limit, page int) (total int, items []T, err error)
+
+// paginate transparently fetches every page of a listing. The remaining
+// pages are fetched concurrently when the first page reports a total, and
+// sequentially otherwise.
+func paginate[T any](c *Client, fetch pageFetcher[T]) ([]T, error) {
+	limit := _pageLimit
+
+	total, first, err := fetch(c, limit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(first) < limit {
+		return first, nil
+	}
+
+	if total <= len(first) {
+		return paginateSequential(c, fetch, first)
+	}
+
+	pages := (total + limit - 1) / limit
+	results := make([][]T, pages)
+	results[0] = first
+
+	ctx, cancel := context.WithCancel(c.context())
+	defer cancel()
+	cc := c.WithContext(ctx)
+
+	sem := make(chan struct{}, c.options.pageConcurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for page := 1; page < pages; page++ {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(page int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, items, err := fetch(cc, limit, page)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+
+			results[page] = items
+		}(page)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	n := 0
+	for _, r := range results {
+		n += len(r)
+	}
+
+	all := make([]T, 0, n)
+	for _, r := range results {
+		all = append(all, r...)
+	}
+
+	// The listing may have grown since the first page was fetched.
+	if len(results[pages-1]) == limit {
+		rest, err := paginateFrom(c, fetch, pages)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rest...)
+	}
+
+	return all, nil
+}
+
+func paginateSequential[T any](c *Client, fetch pageFetcher[T], first []T) ([]T, error) {
+	rest, err := paginateFrom(c, fetch, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(first, rest...), nil
+}
+
+func paginateFrom[T any](c *Client, fetch pageFetcher[T], page int) ([]T, error) {
+	limit := _pageLimit
+
+	var all []T
+	for {
+		_, items, err := fetch(c, limit, page)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, items...)
+
+		if len(items) < limit {
+			break
+		}
+
+		page++
+	}
+
+	return all, nil
+}
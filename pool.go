@@ -0,0 +1,300 @@
+package prospety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrCreditBudgetExceeded is returned by AccountPool when a call would spend
+// more credits than the account has left in its budget.
+var ErrCreditBudgetExceeded = errors.New("credit budget exceeded")
+
+// Account is a named Prospety account in an AccountPool. Every account has
+// its own Client, and with it its own key and rate limiter.
+type Account struct {
+	Name   string
+	Client *Client
+
+	// Credits is the number of credits the pool may spend on the account.
+	// Zero means unlimited.
+	Credits int
+}
+
+type poolAccount struct {
+	Account
+
+	mu    sync.Mutex
+	spent int
+}
+
+// AccountPool routes calls to one of several accounts and runs operations
+// across all of them with a shared concurrency limit.
+type AccountPool struct {
+	accounts map[string]*poolAccount
+	names    []string
+	sem      chan struct{}
+}
+
+// NewAccountPool creates a pool that makes at most concurrency calls at
+// once in its aggregate operations.
+func NewAccountPool(concurrency int, accounts ...Account) (*AccountPool, error) {
+	if concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1: %d", concurrency)
+	}
+
+	p := &AccountPool{
+		accounts: make(map[string]*poolAccount, len(accounts)),
+		sem:      make(chan struct{}, concurrency),
+	}
+
+	for _, a := range accounts {
+		if a.Name == "" {
+			return nil, fmt.Errorf("account name is required")
+		}
+
+		if a.Client == nil {
+			return nil, fmt.Errorf("account %q has no client", a.Name)
+		}
+
+		if _, ok := p.accounts[a.Name]; ok {
+			return nil, fmt.Errorf("duplicate account %q", a.Name)
+		}
+
+		p.accounts[a.Name] = &poolAccount{
+			Account: a,
+		}
+		p.names = append(p.names, a.Name)
+	}
+	sort.Strings(p.names)
+
+	return p, nil
+}
+
+type accountKey struct{}
+
+// WithAccount selects the account AccountPool.Client routes to.
+func WithAccount(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, accountKey{}, name)
+}
+
+func AccountFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(accountKey{}).(string)
+	return name, ok
+}
+
+func (p *AccountPool) Names() []string {
+	res := make([]string, len(p.names))
+	copy(res, p.names)
+
+	return res
+}
+
+func (p *AccountPool) account(name string) (*poolAccount, error) {
+	a, ok := p.accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown account %q", name)
+	}
+
+	return a, nil
+}
+
+// Client returns the client of the account selected with WithAccount,
+// bound to ctx.
+func (p *AccountPool) Client(ctx context.Context) (*Client, error) {
+	name, ok := AccountFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("no account in context")
+	}
+
+	a, err := p.account(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Client.WithContext(ctx), nil
+}
+
+// Each calls fn for every account, at most the pool's concurrency at once.
+// ctx passed to fn carries the account name. Errors are joined and labelled
+// with the account they came from.
+func (p *AccountPool) Each(ctx context.Context, fn func(ctx context.Context, name string, c *Client) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(p.names))
+
+	for i, name := range p.names {
+		select {
+		case p.sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = fmt.Errorf("account %q: %w", name, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-p.sem }()
+
+			actx := WithAccount(ctx, name)
+			err := fn(actx, name, p.accounts[name].Client.WithContext(actx))
+			if err != nil {
+				errs[i] = fmt.Errorf("account %q: %w", name, err)
+			}
+		}(i, name)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+type AccountSearch struct {
+	Account string
+	Search
+}
+
+// GetSearches lists the searches of every account. When some accounts fail,
+// the searches of the others are returned along with the error.
+func (p *AccountPool) GetSearches(ctx context.Context) ([]AccountSearch, error) {
+	var mu sync.Mutex
+	byAccount := make(map[string][]Search, len(p.names))
+
+	err := p.Each(ctx, func(ctx context.Context, name string, c *Client) error {
+		searches, err := c.GetSearches()
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		byAccount[name] = searches
+		mu.Unlock()
+
+		return nil
+	})
+
+	var res []AccountSearch
+	for _, name := range p.names {
+		for _, s := range byAccount[name] {
+			res = append(res, AccountSearch{
+				Account: name,
+				Search:  s,
+			})
+		}
+	}
+
+	return res, err
+}
+
+type AccountQuickSearch struct {
+	Account string
+	QuickSearch
+}
+
+// GetQuickSearches lists the quick searches of every account, like
+// GetSearches.
+func (p *AccountPool) GetQuickSearches(ctx context.Context) ([]AccountQuickSearch, error) {
+	var mu sync.Mutex
+	byAccount := make(map[string][]QuickSearch, len(p.names))
+
+	err := p.Each(ctx, func(ctx context.Context, name string, c *Client) error {
+		quickSearches, err := c.GetQuickSearches()
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		byAccount[name] = quickSearches
+		mu.Unlock()
+
+		return nil
+	})
+
+	var res []AccountQuickSearch
+	for _, name := range p.names {
+		for _, qs := range byAccount[name] {
+			res = append(res, AccountQuickSearch{
+				Account:     name,
+				QuickSearch: qs,
+			})
+		}
+	}
+
+	return res, err
+}
+
+// reserve takes credits from the account budget. The credits are given
+// back with release when the call fails.
+func (a *poolAccount) reserve(credits int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.Credits > 0 && a.spent+credits > a.Credits {
+		return fmt.Errorf("account %q: %w: %d of %d credits spent, %d requested",
+			a.Name, ErrCreditBudgetExceeded, a.spent, a.Credits, credits)
+	}
+	a.spent += credits
+
+	return nil
+}
+
+func (a *poolAccount) release(credits int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.spent -= credits
+}
+
+// Spent returns the credits spent through the pool on an account.
+func (p *AccountPool) Spent(name string) (int, error) {
+	a, err := p.account(name)
+	if err != nil {
+		return 0, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.spent, nil
+}
+
+// CreateSearch creates a search on the account in ctx, charging limit
+// credits against its budget.
+func (p *AccountPool) CreateSearch(ctx context.Context, title string, limit int, searchData any) error {
+	return p.spend(ctx, limit, func(c *Client) error {
+		return c.CreateSearch(title, limit, searchData)
+	})
+}
+
+// CreateQuickSearch creates a quick search on the account in ctx, charging
+// one credit against its budget.
+func (p *AccountPool) CreateQuickSearch(ctx context.Context, channel ChannelType, url string) error {
+	return p.spend(ctx, 1, func(c *Client) error {
+		return c.CreateQuickSearch(channel, url)
+	})
+}
+
+func (p *AccountPool) spend(ctx context.Context, credits int, fn func(c *Client) error) error {
+	name, ok := AccountFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("no account in context")
+	}
+
+	a, err := p.account(name)
+	if err != nil {
+		return err
+	}
+
+	err = a.reserve(credits)
+	if err != nil {
+		return err
+	}
+
+	err = fn(a.Client.WithContext(ctx))
+	if err != nil {
+		a.release(credits)
+		return err
+	}
+
+	return nil
+}
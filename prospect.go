@@ -0,0 +1,198 @@
+package prospety
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// prospectAlias has the fields of Prospect without its methods, so it can
+// be decoded without recursing into UnmarshalJSON.
+type prospectAlias Prospect
+
+func (p *Prospect) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, (*prospectAlias)(p))
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	known := prospectFields()
+	p.Extra = nil
+	for k, v := range fields {
+		if _, ok := known[k]; ok {
+			continue
+		}
+
+		if p.Extra == nil {
+			p.Extra = make(map[string]json.RawMessage)
+		}
+		p.Extra[k] = v
+	}
+
+	return nil
+}
+
+func (p Prospect) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(prospectAlias(p))
+	if err != nil || len(p.Extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range p.Extra {
+		if _, ok := fields[k]; !ok {
+			fields[k] = v
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+var prospectFields = sync.OnceValue(func() map[string]reflect.Type {
+	return jsonFields(reflect.TypeOf(Prospect{}))
+})
+
+// jsonFields returns the JSON names of the fields of t with their types,
+// including those of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	res := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for k, ft := range jsonFields(f.Type) {
+				res[k] = ft
+			}
+			continue
+		}
+
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		res[name] = f.Type
+	}
+
+	return res
+}
+
+// SocialLinks are the links of a prospect sorted by platform.
+type SocialLinks struct {
+	Instagram string
+	TikTok    string
+	Twitter   string
+	Facebook  string
+	LinkedIn  string
+	YouTube   string
+
+	// Website is the first link that isn't a known platform.
+	Website string
+
+	// Other holds any further links, including duplicates for a platform.
+	Other []string
+}
+
+var _socialHosts = map[string]func(s *SocialLinks) *string{
+	"instagram.com": func(s *SocialLinks) *string { return &s.Instagram },
+	"instagr.am":    func(s *SocialLinks) *string { return &s.Instagram },
+	"tiktok.com":    func(s *SocialLinks) *string { return &s.TikTok },
+	"twitter.com":   func(s *SocialLinks) *string { return &s.Twitter },
+	"x.com":         func(s *SocialLinks) *string { return &s.Twitter },
+	"facebook.com":  func(s *SocialLinks) *string { return &s.Facebook },
+	"fb.com":        func(s *SocialLinks) *string { return &s.Facebook },
+	"linkedin.com":  func(s *SocialLinks) *string { return &s.LinkedIn },
+	"youtube.com":   func(s *SocialLinks) *string { return &s.YouTube },
+	"youtu.be":      func(s *SocialLinks) *string { return &s.YouTube },
+}
+
+func (p *Prospect) SocialLinks() SocialLinks {
+	var s SocialLinks
+	for _, link := range p.Links {
+		link = strings.TrimSpace(link)
+		if link == "" {
+			continue
+		}
+
+		field := &s.Website
+		if get, ok := _socialHosts[linkHost(link)]; ok {
+			field = get(&s)
+		}
+
+		if *field == "" {
+			*field = link
+		} else {
+			s.Other = append(s.Other, link)
+		}
+	}
+
+	return s
+}
+
+// linkHost returns the host of link without "www." or "m.", accepting links
+// without a scheme.
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	host = strings.TrimPrefix(host, "m.")
+
+	return host
+}
+
+// Identities returns the keys a prospect is known by. Two prospects that
+// share any key are the same channel.
+//
+// YouTube channels are reachable under several URLs: /channel/UC…, /@handle,
+// /c/name, /user/name and the legacy /name. Only the channel ID is stable,
+// so a handle and a custom URL of the same channel are only recognised as
+// one when some record carries both, e.g. a YouTubeChannelID next to a
+// handle URL. A YouTubeChannelID that is not a valid channel ID is ignored.
+// URLs ParseChannelURL does not understand are compared as they are,
+// ignoring case and a trailing slash.
+func (p *Prospect) Identities() []string {
+	var res []string
+	if _youTubeChannelID.MatchString(p.YouTubeChannelID) {
+		res = append(res, (&ChannelURL{
+			Channel: ChannelYouTube,
+			Kind:    ChannelURLID,
+			ID:      p.YouTubeChannelID,
+		}).Key())
+	}
+
+	raw := strings.TrimSpace(p.URL)
+	if raw == "" {
+		return res
+	}
+
+	if u, err := ParseChannelURL(raw); err == nil {
+		return append(res, u.Key())
+	}
+
+	return append(res, "url:"+strings.TrimSuffix(strings.ToLower(raw), "/"))
+}
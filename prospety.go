@@ -2,38 +2,75 @@ package prospety
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"path"
+	"net/url"
 	"strconv"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"go.uber.org/ratelimit"
 )
 
 const (
-	_pageLimit = 100
+	_pageLimit         = 100
+	_defaultMaxRetries = 3
 )
 
 type Option func(option *options) error
 
 type options struct {
-	host       string
+	baseURL    *url.URL
+	version    *apiVersion
 	rateLimit  *ratelimit.Limiter
 	httpClient *http.Client
+	dryRun     *dryRunRecorder
+
+	logger        *slog.Logger
+	logBodies     bool
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
+
+	instrumentation Instrumentation
+
+	clock      clock.Clock
+	adaptive   *adaptiveLimiter
+	maxRetries int
+	budgets    map[EndpointClass]Budget
+	scheduler  *scheduler
+
+	pageConcurrency int
+
+	cache          Cache
+	cacheTTLs      map[string]time.Duration
+	cacheNamespace string
+
+	inflight *inflight
+
+	tokens TokenSource
+
+	autoIdempotencyKeys bool
+	duplicateWindow     time.Duration
+
+	strictDecoding bool
+	driftHandler   func(err *SchemaDriftError)
 }
 
+// WithHost sets the host, optionally followed by a path prefix, of the
+// HTTPS base URL. Use WithBaseURL for anything else.
 func WithHost(host string) Option {
 	return func(option *options) error {
 		// Check if host is valid.
-		_, err := http.NewRequest("GET", fmt.Sprintf("https://%s", host), nil)
+		u, err := url.Parse(fmt.Sprintf("https://%s", host))
 		if err != nil {
 			return fmt.Errorf("invalid host: %w", err)
 		}
 
-		option.host = host
-		return nil
+		return WithBaseURL(u)(option)
 	}
 }
 
@@ -51,13 +88,38 @@ func WithHttpClient(hc http.Client) Option {
 	}
 }
 
+// WithDryRun makes mutating calls record the request they would have sent
+// instead of sending it. Reads still go through. See Client.DryRunRequests.
+func WithDryRun() Option {
+	return func(option *options) error {
+		option.dryRun = &dryRunRecorder{}
+		return nil
+	}
+}
+
+// WithMaxRetries sets how often a request that was rejected with 429 Too
+// Many Requests is retried. The default is 3.
+func WithMaxRetries(n int) Option {
+	return func(option *options) error {
+		if n < 0 {
+			return fmt.Errorf("max retries must not be negative: %d", n)
+		}
+
+		option.maxRetries = n
+		return nil
+	}
+}
+
 type Client struct {
-	apiKey  string
 	options *options
+	ctx     context.Context
 }
 
 func New(apiKey string, opts ...Option) (*Client, error) {
-	o := &options{}
+	o := &options{
+		maxRetries:      _defaultMaxRetries,
+		pageConcurrency: _defaultPageConcurrency,
+	}
 	for _, opt := range opts {
 		err := opt(o)
 		if err != nil {
@@ -65,8 +127,12 @@ func New(apiKey string, opts ...Option) (*Client, error) {
 		}
 	}
 
-	if o.host == "" {
-		o.host = "app.prospety.com/api"
+	if o.baseURL == nil {
+		u, err := url.Parse(_defaultBaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default base URL: %w", err)
+		}
+		o.baseURL = u
 	}
 
 	if o.rateLimit == nil {
@@ -78,105 +144,255 @@ func New(apiKey string, opts ...Option) (*Client, error) {
 		o.httpClient = http.DefaultClient
 	}
 
+	if o.tokens == nil {
+		o.tokens = StaticToken(apiKey)
+	}
+
+	if o.cache != nil && o.cacheNamespace == "" {
+		ns, err := cacheNamespace(o.tokens)
+		if err != nil {
+			return nil, err
+		}
+		o.cacheNamespace = ns
+	}
+
+	if o.clock == nil {
+		o.clock = clock.New()
+	}
+
+	o.adaptive = newAdaptiveLimiter(o.clock)
+	o.scheduler = newScheduler(o.clock, o.budgets)
+
 	return &Client{
-		apiKey:  apiKey,
 		options: o,
 	}, nil
 }
 
-type param struct {
-	key   string
-	value string
+// WithContext returns a client whose calls use ctx for cancellation,
+// deadlines and priority. The returned client shares everything else with c.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c2 := *c
+	c2.ctx = ctx
+
+	return &c2
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
 }
 
 func (c *Client) buildUrl(p []string) string {
-	return fmt.Sprintf("https://%s/%s", c.options.host, path.Join(p...))
+	// Requests only get here once the version is known, see newRequest.
+	version, _ := c.APIVersion()
+	return c.versionedUrl(version, p)
 }
 
-func (c *Client) buildUrlWithParameters(path []string, params []param) string {
+func (c *Client) buildUrlWithParameters(path []string, q params) string {
 	url := c.buildUrl(path)
-	for i, p := range params {
-		// If it's the first parameter, use a question mark; otherwise, use an ampersand
-		separator := "&"
-		if i == 0 {
-			separator = "?"
-		}
-		url = fmt.Sprintf("%s%s%s=%s", url, separator, p.key, p.value)
+	if len(q) == 0 {
+		return url
 	}
-	return url
+
+	return url + "?" + q.encode()
+}
+
+func (c *Client) do(req *http.Request, path []string) (data []byte, err error) {
+	data, _, err = c.doResponse(req, path)
+	return data, err
 }
 
-func (c *Client) do(req *http.Request) (data []byte, err error) {
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+// doResponse is do, but also returns the response header.
+func (c *Client) doResponse(req *http.Request, path []string) (data []byte, header http.Header, err error) {
+	op := describe(req.Method, path, req.URL.Query())
+	ctx, call := c.startCall(req.Context(), op)
+	req = req.WithContext(ctx)
 
-	(*c.options.rateLimit).Take()
-	resp, err := c.options.httpClient.Do(req)
+	var status int
+	defer func() {
+		call.End(status, err)
+	}()
+
+	token, err := c.options.tokens.Token(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, nil, fmt.Errorf("failed to get API key: %w", err)
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("request failed with status code %d", resp.StatusCode)
+	// The key is set once so that every retry carries the same one.
+	key, err := c.idempotencyKey(ctx, op.Name, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key != "" {
+		req.Header.Set(_idempotencyHeader, key)
 	}
 
-	data, err = io.ReadAll(resp.Body)
+	var retries429 int
+	var refreshed bool
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			call.Retry(attempt)
+
+			err = rewind(req)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		waitStart := c.options.clock.Now()
+		err = c.options.scheduler.wait(ctx, op.Class)
+		if err == nil {
+			(*c.options.rateLimit).Take()
+			err = c.options.adaptive.wait(ctx)
+		}
+		call.RateLimitWait(c.options.clock.Now().Sub(waitStart))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to wait for rate limit: %w", err)
+		}
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+		var resp *http.Response
+		data, resp, err = c.send(req, attempt)
+		if err != nil {
+			return nil, nil, err
+		}
+		status = resp.StatusCode
+
+		if status == http.StatusTooManyRequests && retries429 < c.options.maxRetries {
+			retries429++
+			continue
+		}
+
+		if status == http.StatusUnauthorized && !refreshed {
+			refreshed = true
+			if newToken, ok := c.refreshToken(ctx, token); ok {
+				token = newToken
+				continue
+			}
+		}
+
+		if status < 200 || status >= 300 {
+			return nil, nil, &APIError{
+				StatusCode: status,
+				Header:     resp.Header,
+				Body:       data,
+			}
+		}
+
+		return data, resp.Header, nil
+	}
+}
+
+// send makes a single attempt at req and returns the response with its body
+// already read.
+func (c *Client) send(req *http.Request, attempt int) ([]byte, *http.Response, error) {
+	info := c.onRequest(req, attempt)
+	start := c.options.clock.Now()
+	resp, err := c.options.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		c.onResponse(info, nil, nil, c.options.clock.Now().Sub(start), err)
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	defer resp.Body.Close()
 
-	return data, nil
-}
+	c.options.adaptive.update(resp, attempt)
 
-func (c *Client) get(path []string, params []param) (data []byte, err error) {
-	url := c.buildUrlWithParameters(path, params)
-	req, err := http.NewRequest("GET", url, nil)
+	data, err := io.ReadAll(resp.Body)
+	c.onResponse(info, resp, data, c.options.clock.Now().Sub(start), err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	return c.do(req)
+	return data, resp, nil
 }
 
-func (c *Client) post(path []string, body any) (data []byte, err error) {
-	jsonBody, err := json.Marshal(body)
+// rewind resets the body of req so it can be sent again.
+func rewind(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal body: %w", err)
+		return fmt.Errorf("failed to rewind request body: %w", err)
 	}
+	req.Body = body
 
-	url := c.buildUrl(path)
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
+	return nil
+}
+
+func (c *Client) get(path []string, q params) (data []byte, err error) {
+	req, err := c.newRequest("GET", path, q, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	return c.do(req)
+	return c.coalescedDo(req, path)
+}
+
+func (c *Client) post(path []string, body any) (data []byte, err error) {
+	return c.sendJSON("POST", path, body, true)
 }
 
 func (c *Client) put(path []string, body any) (data []byte, err error) {
+	return c.sendJSON("PUT", path, body, true)
+}
+
+// query sends a read-only request that the API models as a PUT.
+func (c *Client) query(path []string, body any) (data []byte, err error) {
+	return c.sendJSON("PUT", path, body, false)
+}
+
+func (c *Client) sendJSON(method string, path []string, body any, mutating bool) (data []byte, err error) {
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal body: %w", err)
 	}
 
-	url := c.buildUrl(path)
-	req, err := http.NewRequest("PUT", url, bytes.NewReader(jsonBody))
+	if mutating && c.options.dryRun != nil {
+		return c.options.dryRun.record(method, path, jsonBody), nil
+	}
+
+	req, err := c.newRequest(method, path, nil, jsonBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	return c.do(req)
+	data, err = c.do(req, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mutating {
+		c.invalidate(path)
+	}
+
+	return data, nil
 }
 
 func (c *Client) delete(path []string) (data []byte, err error) {
-	url := c.buildUrl(path)
-	req, err := http.NewRequest("DELETE", url, nil)
+	if c.options.dryRun != nil {
+		return c.options.dryRun.record("DELETE", path, nil), nil
+	}
+
+	req, err := c.newRequest("DELETE", path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = c.do(req, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	return c.do(req)
+	c.invalidate(path)
+
+	return data, nil
 }
 
 type getChannelsResponse struct {
@@ -185,55 +401,27 @@ type getChannelsResponse struct {
 }
 
 func (c *Client) GetChannels() ([]Channel, error) {
-	// transparently paginate
-	var channels []Channel
-
-	limit := _pageLimit
-	page := 0
-
-	for {
-		pageChannels, err := c.getChannels(limit, page)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get channels: %w", err)
-		}
-
-		channels = append(channels, pageChannels...)
-
-		if len(pageChannels) < limit {
-			break
-		}
-
-		page++
+	channels, err := paginate(c, (*Client).getChannels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
 	}
 
 	return channels, nil
 }
 
-func (c *Client) getChannels(limit, page int) ([]Channel, error) {
-	// fix 1 indexing in the API
-	page++
-
-	data, err := c.get([]string{"channels"}, []param{
-		{
-			key:   "limit",
-			value: strconv.Itoa(limit),
-		},
-		{
-			key:   "page",
-			value: strconv.Itoa(page),
-		},
-	})
+func (c *Client) getChannels(limit, page int) (int, []Channel, error) {
+	data, err := c.get([]string{"channels"}, pageParams(limit, page))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get channels: %w", err)
+		return 0, nil, fmt.Errorf("failed to get channels: %w", err)
 	}
 
 	res := &getChannelsResponse{}
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetChannels", data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return 0, nil, err
 	}
 
-	return res.Data, nil
+	return res.Total, res.Data, nil
 }
 
 func (c *Client) GetChannel(id int) (*Channel, error) {
@@ -243,9 +431,9 @@ func (c *Client) GetChannel(id int) (*Channel, error) {
 	}
 
 	res := &Channel{}
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetChannel", data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, err
 	}
 
 	return res, nil
@@ -257,55 +445,27 @@ type getQuickSearchesResponse struct {
 }
 
 func (c *Client) GetQuickSearches() ([]QuickSearch, error) {
-	// transparently paginate
-	var quickSearches []QuickSearch
-
-	limit := _pageLimit
-	page := 0
-
-	for {
-		pageQuickSearches, err := c.getQuickSearches(limit, page)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get quick searches: %w", err)
-		}
-
-		quickSearches = append(quickSearches, pageQuickSearches...)
-
-		if len(pageQuickSearches) < limit {
-			break
-		}
-
-		page++
+	quickSearches, err := paginate(c, (*Client).getQuickSearches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quick searches: %w", err)
 	}
 
 	return quickSearches, nil
 }
 
-func (c *Client) getQuickSearches(limit, page int) ([]QuickSearch, error) {
-	// fix 1 indexing in the API
-	page++
-
-	data, err := c.get([]string{"quick_searches"}, []param{
-		{
-			key:   "limit",
-			value: strconv.Itoa(limit),
-		},
-		{
-			key:   "page",
-			value: strconv.Itoa(page),
-		},
-	})
+func (c *Client) getQuickSearches(limit, page int) (int, []QuickSearch, error) {
+	data, err := c.get([]string{"quick_searches"}, pageParams(limit, page))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get quick searches: %w", err)
+		return 0, nil, fmt.Errorf("failed to get quick searches: %w", err)
 	}
 
 	res := &getQuickSearchesResponse{}
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetQuickSearches", data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return 0, nil, err
 	}
 
-	return res.Data, nil
+	return res.Total, res.Data, nil
 }
 
 type createQuickSearchPayload struct {
@@ -313,18 +473,87 @@ type createQuickSearchPayload struct {
 	Url       string `json:"url"`
 }
 
+// CreateQuickSearch creates a quick search for a channel URL in any of the
+// forms ParseChannelURL accepts. The URL is sent in canonical form, or as
+// given when it has parts beyond the channel, such as /videos. URLs
+// that are not a channel of the given type are rejected before anything is
+// sent.
 func (c *Client) CreateQuickSearch(channel ChannelType, url string) error {
+	_, err := c.createQuickSearch(channel, url, nil)
+	return err
+}
+
+// createQuickSearch creates a quick search and returns it as far as the
+// response describes it. Its ID is 0 when the response has none, e.g. in
+// dry-run mode. Reused and duplicate quick searches are looked up in idx,
+// which is fetched when nil and needed.
+func (c *Client) createQuickSearch(channel ChannelType, url string, idx *QuickSearchIndex) (*QuickSearch, error) {
+	u, err := ParseChannelURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quick search: %w", err)
+	}
+
+	if u.Channel != channel {
+		return nil, fmt.Errorf("failed to create quick search: %q is not a %s URL", url, channelName(channel))
+	}
+
+	if idx == nil && (c.quickSearchReuse() > 0 || c.options.duplicateWindow > 0) {
+		idx, err = c.QuickSearchIndex()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create quick search: failed to check for existing quick search: %w", err)
+		}
+	}
+
+	if idx != nil {
+		if qs := c.reusableQuickSearch(idx, u.String()); qs != nil {
+			return qs, nil
+		}
+
+		if qs := c.duplicateQuickSearch(idx, u.String()); qs != nil {
+			return qs, nil
+		}
+	}
+
 	payload := createQuickSearchPayload{
 		ChannelID: channel,
-		Url:       url,
+		Url:       u.requestURL(),
+	}
+
+	data, err := c.post([]string{"quick_searches"}, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quick search: %w", err)
+	}
+
+	// The created quick search is a bonus; the creation succeeded either
+	// way.
+	res := &QuickSearch{}
+	if len(bytes.TrimSpace(data)) == 0 || c.decode("CreateQuickSearch", data, res) != nil {
+		return &QuickSearch{}, nil
 	}
 
-	_, err := c.post([]string{"quick_searches"}, payload)
+	return res, nil
+}
+
+// CreateQuickSearchURL creates a quick search for url on the platform the
+// URL belongs to.
+func (c *Client) CreateQuickSearchURL(url string) error {
+	u, err := ParseChannelURL(url)
 	if err != nil {
 		return fmt.Errorf("failed to create quick search: %w", err)
 	}
 
-	return nil
+	return c.CreateQuickSearch(u.Channel, url)
+}
+
+func channelName(channel ChannelType) string {
+	switch channel {
+	case ChannelYouTube:
+		return "YouTube"
+	case ChannelInstagram:
+		return "Instagram"
+	default:
+		return fmt.Sprintf("channel %d", channel)
+	}
 }
 
 func (c *Client) GetQuickSearch(id int) (*QuickSearch, error) {
@@ -334,9 +563,9 @@ func (c *Client) GetQuickSearch(id int) (*QuickSearch, error) {
 	}
 
 	res := &QuickSearch{}
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetQuickSearch", data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, err
 	}
 
 	return res, nil
@@ -379,36 +608,43 @@ func getPotentialProspectsCountYouTubeStandard(c *Client, criteria *StandardSear
 		Data:      criteria,
 	}
 
-	data, err := c.put([]string{"searches", "potential-prospects", "count"}, payload)
+	data, err := c.query([]string{"searches", "potential-prospects", "count"}, payload)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get potential prospects count: %w", err)
 	}
 
 	res := &getPotentialProspectsCountResponse{}
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetPotentialProspectsCount", data, &res)
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal response: %w", err)
+		return 0, err
 	}
 
 	return res.Count, nil
 }
 
 func getPotentialProspectsCountYouTubeSimilar(c *Client, criteria *SimilarSearchCriteria) (int, error) {
+	refs, err := normalizeReferences(criteria.References)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get potential prospects count: %w", err)
+	}
+	normalized := *criteria
+	normalized.References = refs
+
 	payload := getPotentialProspectsCountPayload{
 		Type:      SearchTypeSimilar,
 		ChannelId: ChannelYouTube,
-		Data:      criteria,
+		Data:      &normalized,
 	}
 
-	data, err := c.put([]string{"searches", "potential-prospects", "count"}, payload)
+	data, err := c.query([]string{"searches", "potential-prospects", "count"}, payload)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get potential prospects count: %w", err)
 	}
 
 	res := &getPotentialProspectsCountResponse{}
-	err = json.Unmarshal(data, res)
+	err = c.decode("GetPotentialProspectsCount", data, res)
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal response: %w", err)
+		return 0, err
 	}
 
 	return res.Count, nil
@@ -434,36 +670,43 @@ func getPotentialProspectsYouTubeStandard(c *Client, criteria *StandardSearchCri
 		Data:      criteria,
 	}
 
-	data, err := c.put([]string{"searches", "potential-prospects", "preview"}, payload)
+	data, err := c.query([]string{"searches", "potential-prospects", "preview"}, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get potential prospects: %w", err)
 	}
 
 	var res []ProspectPreview
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetPotentialProspects", data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, err
 	}
 
 	return res, nil
 }
 
 func getPotentialProspectsYouTubeSimilar(c *Client, criteria *SimilarSearchCriteria) ([]ProspectPreview, error) {
+	refs, err := normalizeReferences(criteria.References)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get potential prospects: %w", err)
+	}
+	normalized := *criteria
+	normalized.References = refs
+
 	payload := getPotentialProspectsPayload{
 		Type:      SearchTypeSimilar,
 		ChannelId: ChannelYouTube,
-		Data:      criteria,
+		Data:      &normalized,
 	}
 
-	data, err := c.put([]string{"searches", "potential-prospects", "preview"}, payload)
+	data, err := c.query([]string{"searches", "potential-prospects", "preview"}, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get potential prospects: %w", err)
 	}
 
 	var res []ProspectPreview
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetPotentialProspects", data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, err
 	}
 
 	return res, nil
@@ -475,55 +718,27 @@ type getSearchesResponse struct {
 }
 
 func (c *Client) GetSearches() ([]Search, error) {
-	// transparently gets all pages
-	var res []Search
-
-	limit := _pageLimit
-	page := 0
-
-	for {
-		searches, err := c.getSearches(limit, page)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get searches: %w", err)
-		}
-
-		res = append(res, searches...)
-
-		if len(searches) < limit {
-			break
-		}
-
-		page++
+	res, err := paginate(c, (*Client).getSearches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get searches: %w", err)
 	}
 
 	return res, nil
 }
 
-func (c *Client) getSearches(limit, page int) ([]Search, error) {
-	// fix 1 indexing in API
-	page++
-
-	data, err := c.get([]string{"searches"}, []param{
-		{
-			key:   "limit",
-			value: strconv.Itoa(limit),
-		},
-		{
-			key:   "page",
-			value: strconv.Itoa(page),
-		},
-	})
+func (c *Client) getSearches(limit, page int) (int, []Search, error) {
+	data, err := c.get([]string{"searches"}, pageParams(limit, page))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get searches: %w", err)
+		return 0, nil, fmt.Errorf("failed to get searches: %w", err)
 	}
 
 	res := &getSearchesResponse{}
-	err = json.Unmarshal(data, &res)
+	err = c.decode("GetSearches", data, &res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return 0, nil, err
 	}
 
-	return res.Data, nil
+	return res.Total, res.Data, nil
 }
 
 type createSearchPayload struct {
@@ -546,6 +761,14 @@ func (c *Client) CreateSearch(title string, limit int, searchData any) error {
 }
 
 func createSearchYouTubeStandard(c *Client, title string, limit int, data *StandardSearch) error {
+	dup, err := c.duplicateSearch(title, limit, *data)
+	if err != nil {
+		return fmt.Errorf("failed to create search: %w", err)
+	}
+	if dup {
+		return nil
+	}
+
 	payload := createSearchPayload{
 		Title:     title,
 		Type:      SearchTypeStandard,
@@ -555,7 +778,7 @@ func createSearchYouTubeStandard(c *Client, title string, limit int, data *Stand
 		Method:    "PUT",
 	}
 
-	_, err := c.put([]string{"searches"}, payload)
+	_, err = c.put([]string{"searches"}, payload)
 	if err != nil {
 		return fmt.Errorf("failed to create search: %w", err)
 	}
@@ -570,9 +793,9 @@ func (c *Client) GetSearch(id int) (*Search, error) {
 	}
 
 	res := &Search{}
-	err = json.Unmarshal(data, res)
+	err = c.decode("GetSearch", data, res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, err
 	}
 
 	return res, nil
@@ -655,9 +878,9 @@ func (c *Client) GetProspects(id int) ([]Prospect, error) {
 	}
 
 	res := &getProspectsResponse{}
-	err = json.Unmarshal(data, res)
+	err = c.decode("GetProspects", data, res)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, err
 	}
 
 	return res.Data, nil
@@ -665,12 +888,7 @@ func (c *Client) GetProspects(id int) ([]Prospect, error) {
 
 func (c *Client) ExportProspects(id int, fileType string) (string, error) {
 	data, err := c.get([]string{"searches", strconv.Itoa(id), "prospects", "export"},
-		[]param{
-			{
-				key:   "type",
-				value: fileType,
-			},
-		})
+		newParams().set("type", fileType))
 	if err != nil {
 		return "", fmt.Errorf("failed to export prospects: %w", err)
 	}
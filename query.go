@@ -0,0 +1,49 @@
+package prospety
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// params builds the query string of a request. Values are escaped when the
+// URL is built.
+type params url.Values
+
+func newParams() params {
+	return make(params)
+}
+
+// pageParams are the parameters of a page of a listing. page is 0 indexed.
+func pageParams(limit, page int) params {
+	// fix 1 indexing in the API
+	return newParams().
+		setInt("limit", limit).
+		setInt("page", page+1)
+}
+
+func (q params) set(key, value string) params {
+	url.Values(q).Set(key, value)
+	return q
+}
+
+func (q params) setInt(key string, value int) params {
+	return q.set(key, strconv.Itoa(value))
+}
+
+// add appends a value, so the key may be repeated.
+func (q params) add(key, value string) params {
+	url.Values(q).Add(key, value)
+	return q
+}
+
+// addArray appends values as an array parameter, e.g. country[]=US.
+func (q params) addArray(key string, values ...string) params {
+	for _, v := range values {
+		q.add(key+"[]", v)
+	}
+	return q
+}
+
+func (q params) encode() string {
+	return url.Values(q).Encode()
+}
@@ -0,0 +1,33 @@
+package prospety
+
+import "testing"
+
+func TestParamsEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		q    params
+		want string
+	}{
+		{"empty", newParams(), ""},
+		{"page", pageParams(50, 0), "limit=50&page=1"},
+		{"set replaces", newParams().set("type", "csv").set("type", "xlsx"), "type=xlsx"},
+		{"repeated key", newParams().add("tag", "a").add("tag", "b"), "tag=a&tag=b"},
+		{"array", newParams().addArray("country", "US", "GB"), "country%5B%5D=US&country%5B%5D=GB"},
+		{"sorted by key", newParams().set("b", "2").set("a", "1"), "a=1&b=2"},
+
+		// An export file type is passed through as typed, so it must not be
+		// able to add parameters of its own.
+		{"ampersand", newParams().set("type", "csv&limit=1"), "type=csv%26limit%3D1"},
+		{"space", newParams().set("type", "c s v"), "type=c+s+v"},
+		{"non-ASCII", newParams().set("type", "tablé"), "type=tabl%C3%A9"},
+		{"escaped key", newParams().set("a b", "1"), "a+b=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.encode(); got != tt.want {
+				t.Errorf("encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package prospety
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// QuickSearchIndex finds quick searches by the URL of the prospect they
+// found. URLs are compared by channel, so every form ParseChannelURL
+// accepts matches, but a handle URL does not match the channel ID URL of
+// the same channel. Quick searches that have not found a prospect yet are
+// not indexed.
+type QuickSearchIndex struct {
+	byKey map[string][]QuickSearch
+}
+
+func NewQuickSearchIndex(quickSearches []QuickSearch) *QuickSearchIndex {
+	idx := &QuickSearchIndex{
+		byKey: make(map[string][]QuickSearch),
+	}
+
+	for _, qs := range quickSearches {
+		if qs.Prospect == nil || qs.Prospect.URL == "" {
+			continue
+		}
+
+		k := channelKey(qs.Prospect.URL)
+		idx.byKey[k] = append(idx.byKey[k], qs)
+	}
+
+	for _, list := range idx.byKey {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].CreatedAt.After(list[j].CreatedAt.Time)
+		})
+	}
+
+	return idx
+}
+
+// QuickSearchIndex fetches every quick search of the account and indexes
+// them.
+func (c *Client) QuickSearchIndex() (*QuickSearchIndex, error) {
+	quickSearches, err := c.GetQuickSearches()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewQuickSearchIndex(quickSearches), nil
+}
+
+// Lookup returns the quick searches for url, newest first.
+func (idx *QuickSearchIndex) Lookup(url string) []QuickSearch {
+	return idx.byKey[channelKey(url)]
+}
+
+// Find returns the newest quick search for url.
+func (idx *QuickSearchIndex) Find(url string) (*QuickSearch, bool) {
+	list := idx.Lookup(url)
+	if len(list) == 0 {
+		return nil, false
+	}
+
+	return &list[0], true
+}
+
+// FindQuickSearchByURL returns the newest quick search that found the
+// prospect at url, or nil if there is none. Use QuickSearchIndex to look up
+// many URLs with a single listing.
+func (c *Client) FindQuickSearchByURL(url string) (*QuickSearch, error) {
+	idx, err := c.QuickSearchIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find quick search: %w", err)
+	}
+
+	qs, _ := idx.Find(url)
+	return qs, nil
+}
+
+type quickSearchReuseKey struct{}
+
+// WithQuickSearchReuse makes quick searches created through
+// Client.WithContext(ctx) return a finished quick search for the same URL
+// instead of creating a new one, as long as it finished no longer than
+// maxAge ago. No credits are spent on a reused result.
+func WithQuickSearchReuse(ctx context.Context, maxAge time.Duration) context.Context {
+	return context.WithValue(ctx, quickSearchReuseKey{}, maxAge)
+}
+
+func (c *Client) quickSearchReuse() time.Duration {
+	maxAge, _ := c.context().Value(quickSearchReuseKey{}).(time.Duration)
+	return maxAge
+}
+
+// reusableQuickSearch returns a finished quick search for url in idx that
+// is fresh enough to be reused, if reuse was asked for.
+func (c *Client) reusableQuickSearch(idx *QuickSearchIndex, url string) *QuickSearch {
+	maxAge := c.quickSearchReuse()
+	if maxAge <= 0 {
+		return nil
+	}
+
+	return idx.reusable(url, c.options.clock.Now(), maxAge)
+}
+
+// reusable returns the newest quick search for url that finished at most
+// maxAge before now.
+func (idx *QuickSearchIndex) reusable(url string, now time.Time, maxAge time.Duration) *QuickSearch {
+	for _, qs := range idx.Lookup(url) {
+		if qs.Status != SearchStatusFinished {
+			continue
+		}
+
+		finished := qs.UpdatedAt.Time
+		if finished.IsZero() {
+			finished = qs.CreatedAt.Time
+		}
+
+		if now.Sub(finished) <= maxAge {
+			return &qs
+		}
+	}
+
+	return nil
+}
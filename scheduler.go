@@ -0,0 +1,229 @@
+package prospety
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+)
+
+// EndpointClass groups endpoints that share a rate limit budget.
+type EndpointClass string
+
+const (
+	// ClassListing covers every read except previews and exports.
+	ClassListing  = EndpointClass("listing")
+	ClassMutation = EndpointClass("mutation")
+	ClassPreview  = EndpointClass("preview")
+	ClassExport   = EndpointClass("export")
+)
+
+type Priority int
+
+const (
+	PriorityLow    = Priority(-1)
+	PriorityNormal = Priority(0)
+	PriorityHigh   = Priority(1)
+)
+
+type priorityKey struct{}
+
+// WithPriority tags the calls made through Client.WithContext(ctx). Within
+// an endpoint class, waiting calls with a higher priority are sent first.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) Priority {
+	p, ok := ctx.Value(priorityKey{}).(Priority)
+	if !ok {
+		return PriorityNormal
+	}
+
+	return p
+}
+
+// Budget allows Limit requests Per duration, with up to Burst requests sent
+// back to back after a quiet period.
+type Budget struct {
+	Limit int
+	Per   time.Duration
+	Burst int
+}
+
+// WithBudget limits the requests of one endpoint class. Classes without a
+// budget are only limited by WithRateLimit.
+func WithBudget(class EndpointClass, b Budget) Option {
+	return func(option *options) error {
+		if b.Limit <= 0 || b.Per <= 0 {
+			return fmt.Errorf("invalid budget for %s: %d per %s", class, b.Limit, b.Per)
+		}
+
+		if b.Burst < 1 {
+			b.Burst = 1
+		}
+
+		if option.budgets == nil {
+			option.budgets = make(map[EndpointClass]Budget)
+		}
+		option.budgets[class] = b
+		return nil
+	}
+}
+
+// WithClock replaces the clock used for rate limiting, so that tests can
+// drive the client with clock.NewMock().
+func WithClock(c clock.Clock) Option {
+	return func(option *options) error {
+		option.clock = c
+		return nil
+	}
+}
+
+type scheduler struct {
+	queues map[EndpointClass]*budgetQueue
+}
+
+func newScheduler(c clock.Clock, budgets map[EndpointClass]Budget) *scheduler {
+	s := &scheduler{
+		queues: make(map[EndpointClass]*budgetQueue, len(budgets)),
+	}
+
+	for class, b := range budgets {
+		s.queues[class] = &budgetQueue{
+			clock:    c,
+			interval: b.Per / time.Duration(b.Limit),
+			burst:    b.Burst,
+		}
+	}
+
+	return s
+}
+
+// wait blocks until the class budget allows another request.
+func (s *scheduler) wait(ctx context.Context, class EndpointClass) error {
+	q, ok := s.queues[class]
+	if !ok {
+		return nil
+	}
+
+	return q.wait(ctx, priorityFrom(ctx))
+}
+
+// budgetQueue hands out evenly spaced request slots to waiters in priority
+// order, FIFO within the same priority.
+type budgetQueue struct {
+	clock    clock.Clock
+	interval time.Duration
+	burst    int
+
+	mu      sync.Mutex
+	next    time.Time
+	seq     uint64
+	waiters waiterHeap
+	timer   *clock.Timer
+}
+
+type waiter struct {
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+func (q *budgetQueue) wait(ctx context.Context, p Priority) error {
+	q.mu.Lock()
+	q.seq++
+	w := &waiter{
+		priority: p,
+		seq:      q.seq,
+		ready:    make(chan struct{}),
+	}
+	heap.Push(&q.waiters, w)
+	q.dispatch()
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		// Dispatched while we were cancelled; the slot is spent either way.
+	default:
+		heap.Remove(&q.waiters, w.index)
+	}
+
+	return ctx.Err()
+}
+
+// dispatch releases every waiter whose slot has come and arms a timer for
+// the rest. It must be called with q.mu held.
+func (q *budgetQueue) dispatch() {
+	now := q.clock.Now()
+
+	// Unused slots accumulate up to the burst size.
+	earliest := now.Add(-time.Duration(q.burst-1) * q.interval)
+	if q.next.Before(earliest) {
+		q.next = earliest
+	}
+
+	for q.waiters.Len() > 0 && !q.next.After(now) {
+		w := heap.Pop(&q.waiters).(*waiter)
+		close(w.ready)
+		q.next = q.next.Add(q.interval)
+	}
+
+	if q.waiters.Len() == 0 || q.timer != nil {
+		return
+	}
+
+	q.timer = q.clock.AfterFunc(q.next.Sub(now), func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		q.timer = nil
+		q.dispatch()
+	})
+}
+
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+
+	return w
+}
@@ -0,0 +1,200 @@
+package prospety
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+)
+
+// queued waits until n calls are waiting in the queue of class.
+func queued(t *testing.T, s *scheduler, class EndpointClass, n int) {
+	t.Helper()
+
+	q := s.queues[class]
+	deadline := time.Now().Add(time.Second)
+	for {
+		q.mu.Lock()
+		got := q.waiters.Len()
+		q.mu.Unlock()
+
+		if got == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d calls waiting for %s, want %d", got, class, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// released returns the next value sent on done, failing if there is none
+// within a second of real time.
+func released[T any](t *testing.T, done <-chan T) T {
+	t.Helper()
+
+	select {
+	case v := <-done:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("no call was released")
+		panic("unreachable")
+	}
+}
+
+func notReleased[T any](t *testing.T, done <-chan T) {
+	t.Helper()
+
+	select {
+	case v := <-done:
+		t.Fatalf("call %v was released early", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	mock := clock.NewMock()
+	s := newScheduler(mock, map[EndpointClass]Budget{
+		ClassListing: {Limit: 1, Per: time.Second, Burst: 1},
+	})
+
+	// Spend the only slot so that the rest have to queue.
+	err := s.wait(context.Background(), ClassListing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := []struct {
+		name     string
+		priority Priority
+	}{
+		{"low", PriorityLow},
+		{"normal 1", PriorityNormal},
+		{"high", PriorityHigh},
+		{"normal 2", PriorityNormal},
+	}
+
+	done := make(chan string)
+	for i, c := range calls {
+		go func(name string, p Priority) {
+			ctx := WithPriority(context.Background(), p)
+			if err := s.wait(ctx, ClassListing); err != nil {
+				t.Error(err)
+			}
+			done <- name
+		}(c.name, c.priority)
+		// Queue the calls in order, so that FIFO within a priority is
+		// observable.
+		queued(t, s, ClassListing, i+1)
+	}
+
+	want := []string{"high", "normal 1", "normal 2", "low"}
+	for _, w := range want {
+		notReleased(t, done)
+		mock.Add(time.Second)
+
+		if got := released(t, done); got != w {
+			t.Errorf("released %q, want %q", got, w)
+		}
+	}
+}
+
+func TestSchedulerBudgets(t *testing.T) {
+	mock := clock.NewMock()
+	s := newScheduler(mock, map[EndpointClass]Budget{
+		ClassListing:  {Limit: 2, Per: time.Second, Burst: 2},
+		ClassMutation: {Limit: 1, Per: time.Minute, Burst: 1},
+	})
+	ctx := context.Background()
+
+	// The burst is available right away.
+	for i := 0; i < 2; i++ {
+		err := s.wait(ctx, ClassListing)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan EndpointClass, 2)
+	go func() {
+		if err := s.wait(ctx, ClassListing); err != nil {
+			t.Error(err)
+		}
+		done <- ClassListing
+	}()
+	queued(t, s, ClassListing, 1)
+	notReleased(t, done)
+
+	// Other classes have budgets of their own, and classes without one are
+	// not held back at all.
+	for _, class := range []EndpointClass{ClassMutation, ClassExport, ClassExport} {
+		err := s.wait(ctx, class)
+		if err != nil {
+			t.Fatalf("%s: %v", class, err)
+		}
+	}
+
+	go func() {
+		if err := s.wait(ctx, ClassMutation); err != nil {
+			t.Error(err)
+		}
+		done <- ClassMutation
+	}()
+	queued(t, s, ClassMutation, 1)
+
+	// Listings are spaced by Per/Limit.
+	mock.Add(499 * time.Millisecond)
+	notReleased(t, done)
+	mock.Add(time.Millisecond)
+	if got := released(t, done); got != ClassListing {
+		t.Fatalf("released %s, want %s", got, ClassListing)
+	}
+
+	mock.Add(59 * time.Second)
+	notReleased(t, done)
+	mock.Add(time.Second)
+	if got := released(t, done); got != ClassMutation {
+		t.Fatalf("released %s, want %s", got, ClassMutation)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	mock := clock.NewMock()
+	s := newScheduler(mock, map[EndpointClass]Budget{
+		ClassListing: {Limit: 1, Per: time.Second, Burst: 1},
+	})
+
+	err := s.wait(context.Background(), ClassListing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(WithPriority(context.Background(), PriorityHigh))
+	cancelled := make(chan error)
+	go func() {
+		cancelled <- s.wait(ctx, ClassListing)
+	}()
+	queued(t, s, ClassListing, 1)
+
+	done := make(chan string)
+	go func() {
+		if err := s.wait(context.Background(), ClassListing); err != nil {
+			t.Error(err)
+		}
+		done <- "normal"
+	}()
+	queued(t, s, ClassListing, 2)
+
+	cancel()
+	if err := released(t, cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled wait returned %v, want %v", err, context.Canceled)
+	}
+	queued(t, s, ClassListing, 1)
+
+	// The cancelled call gives its slot to the next one.
+	notReleased(t, done)
+	mock.Add(time.Second)
+	released(t, done)
+}
@@ -0,0 +1,292 @@
+// Package score ranks prospects by a weighted combination of their stats.
+//
+//	cfg := score.ForSearch(search, score.DefaultConfig())
+//	for _, r := range score.Rank(prospects, cfg) {
+//		fmt.Println(r.Rank, r.Prospect.Name, r.Score)
+//		for _, c := range r.Breakdown {
+//			fmt.Printf("  %s: %.2f × %.1f\n", c.Feature, c.Value, c.Weight)
+//		}
+//	}
+package score
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+// Feature is one aspect of a prospect that contributes to its score. Every
+// feature is valued between 0 and 1.
+type Feature string
+
+const (
+	// Subscribers, TotalViews and ViewsPerVideo are valued on a log scale
+	// relative to the highest value among the ranked prospects.
+	Subscribers   Feature = "subscribers"
+	TotalViews    Feature = "total_views"
+	ViewsPerVideo Feature = "views_per_video"
+
+	// Recency halves with every RecencyHalfLife since the last video.
+	Recency Feature = "recency"
+
+	// Email is 1 for an email that is not known to be invalid.
+	Email Feature = "email"
+
+	// Phone is 1 for any phone number.
+	Phone Feature = "phone"
+
+	// Country is 1 for a prospect in one of Config.Countries.
+	Country Feature = "country"
+
+	// Keywords is the fraction of Config.Keywords found in the prospect's
+	// keywords, video keywords, name or description.
+	Keywords Feature = "keywords"
+)
+
+// Features are all features in the order they appear in breakdowns.
+var Features = []Feature{Subscribers, TotalViews, ViewsPerVideo, Recency, Email, Phone, Country, Keywords}
+
+const _defaultRecencyHalfLife = 30 * 24 * time.Hour
+
+type Config struct {
+	// Weights of the features. Features without a weight are ignored.
+	Weights map[Feature]float64
+
+	// Countries are the wanted country codes. Country is ignored when
+	// there are none.
+	Countries []string
+
+	// Keywords are the wanted keywords. Keywords is ignored when there are
+	// none.
+	Keywords []string
+
+	// RecencyHalfLife is the age of the last video at which Recency is
+	// 0.5. The default is 30 days.
+	RecencyHalfLife time.Duration
+
+	// Now is the time Recency is measured from. The default is the time of
+	// ranking.
+	Now time.Time
+}
+
+// DefaultConfig weighs reach highest, followed by contactability and
+// activity.
+func DefaultConfig() Config {
+	return Config{
+		Weights: map[Feature]float64{
+			Subscribers:   3,
+			TotalViews:    1,
+			ViewsPerVideo: 2,
+			Recency:       2,
+			Email:         2,
+			Phone:         0.5,
+			Country:       1,
+			Keywords:      2,
+		},
+		RecencyHalfLife: _defaultRecencyHalfLife,
+	}
+}
+
+// ForSearch returns cfg with the countries and keywords of the search the
+// prospects came from.
+func ForSearch(s *prospety.Search, cfg Config) Config {
+	cfg.Countries = s.Data.Country
+	cfg.Keywords = s.Data.Keywords
+
+	return cfg
+}
+
+// Contribution is the part of a score that comes from one feature.
+type Contribution struct {
+	Feature Feature
+	Value   float64
+	Weight  float64
+
+	// Score is Value × Weight divided by the sum of the weights, so the
+	// scores of a breakdown add up to Result.Score.
+	Score float64
+}
+
+type Result struct {
+	Prospect prospety.Prospect
+
+	// Score is between 0 and 1.
+	Score     float64
+	Breakdown []Contribution
+
+	// Rank is 1 for the best prospect.
+	Rank int
+}
+
+// Rank scores every prospect and sorts them best first. Ties are broken by
+// subscribers, then by the original order.
+func Rank(prospects []prospety.Prospect, cfg Config) []Result {
+	if cfg.RecencyHalfLife <= 0 {
+		cfg.RecencyHalfLife = _defaultRecencyHalfLife
+	}
+	if cfg.Now.IsZero() {
+		cfg.Now = time.Now()
+	}
+
+	s := newScorer(prospects, cfg)
+
+	res := make([]Result, len(prospects))
+	for i, p := range prospects {
+		res[i] = s.score(p)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Prospect.Subscribers > res[j].Prospect.Subscribers
+	})
+
+	for i := range res {
+		res[i].Rank = i + 1
+	}
+
+	return res
+}
+
+type scorer struct {
+	cfg       Config
+	features  []Feature
+	total     float64
+	max       map[Feature]float64
+	countries map[string]bool
+	keywords  []string
+}
+
+func newScorer(prospects []prospety.Prospect, cfg Config) *scorer {
+	s := &scorer{
+		cfg:       cfg,
+		max:       make(map[Feature]float64),
+		countries: make(map[string]bool),
+	}
+
+	for _, c := range cfg.Countries {
+		s.countries[strings.ToUpper(strings.TrimSpace(c))] = true
+	}
+
+	for _, k := range cfg.Keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			s.keywords = append(s.keywords, k)
+		}
+	}
+
+	for _, f := range Features {
+		w := cfg.Weights[f]
+		if w <= 0 || (f == Country && len(s.countries) == 0) || (f == Keywords && len(s.keywords) == 0) {
+			continue
+		}
+
+		s.features = append(s.features, f)
+		s.total += w
+	}
+
+	for _, p := range prospects {
+		for _, f := range []Feature{Subscribers, TotalViews, ViewsPerVideo} {
+			s.max[f] = math.Max(s.max[f], count(f, &p))
+		}
+	}
+
+	return s
+}
+
+func (s *scorer) score(p prospety.Prospect) Result {
+	res := Result{
+		Prospect:  p,
+		Breakdown: make([]Contribution, 0, len(s.features)),
+	}
+
+	for _, f := range s.features {
+		c := Contribution{
+			Feature: f,
+			Value:   s.value(f, &p),
+			Weight:  s.cfg.Weights[f],
+		}
+		if s.total > 0 {
+			c.Score = c.Value * c.Weight / s.total
+		}
+
+		res.Score += c.Score
+		res.Breakdown = append(res.Breakdown, c)
+	}
+
+	return res
+}
+
+func (s *scorer) value(f Feature, p *prospety.Prospect) float64 {
+	switch f {
+	case Subscribers, TotalViews, ViewsPerVideo:
+		if s.max[f] <= 0 {
+			return 0
+		}
+		return math.Log1p(count(f, p)) / math.Log1p(s.max[f])
+	case Recency:
+		if p.LastVideo.IsZero() {
+			return 0
+		}
+		age := s.cfg.Now.Sub(p.LastVideo.Time)
+		if age <= 0 {
+			return 1
+		}
+		return math.Pow(0.5, float64(age)/float64(s.cfg.RecencyHalfLife))
+	case Email:
+		return boolValue(p.Email != "" && p.EmailStatus != prospety.EmailStatusInvalid)
+	case Phone:
+		return boolValue(p.Phone != "")
+	case Country:
+		return boolValue(s.countries[strings.ToUpper(p.Country)])
+	case Keywords:
+		return s.keywordMatch(p)
+	default:
+		return 0
+	}
+}
+
+func (s *scorer) keywordMatch(p *prospety.Prospect) float64 {
+	var text []string
+	text = append(text, p.Keywords...)
+	text = append(text, p.VideoKeywords...)
+	text = append(text, p.Name, p.Description)
+	haystack := strings.ToLower(strings.Join(text, "\n"))
+
+	matched := 0
+	for _, k := range s.keywords {
+		if strings.Contains(haystack, k) {
+			matched++
+		}
+	}
+
+	return float64(matched) / float64(len(s.keywords))
+}
+
+// count returns the raw value of a count feature.
+func count(f Feature, p *prospety.Prospect) float64 {
+	switch f {
+	case Subscribers:
+		return float64(p.Subscribers)
+	case TotalViews:
+		return float64(p.TotalViews)
+	case ViewsPerVideo:
+		if p.TotalVideos == 0 {
+			return 0
+		}
+		return float64(p.TotalViews) / float64(p.TotalVideos)
+	default:
+		return 0
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
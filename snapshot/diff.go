@@ -0,0 +1,277 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+// Diff is what changed in a search between two snapshots.
+type Diff struct {
+	SearchID int
+	From, To time.Time
+
+	// Added and Removed are in the order of the snapshot they are in.
+	Added   []prospety.Prospect
+	Removed []prospety.Prospect
+	Changed []Change
+}
+
+// Empty reports whether nothing changed.
+func (d *Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Change is a prospect in both snapshots whose fields differ.
+type Change struct {
+	Before, After prospety.Prospect
+
+	// Fields are sorted by name.
+	Fields []FieldDelta
+}
+
+// Delta returns the change of a field, and false if it did not change.
+func (c *Change) Delta(field string) (FieldDelta, bool) {
+	for _, f := range c.Fields {
+		if f.Field == field {
+			return f, true
+		}
+	}
+
+	return FieldDelta{}, false
+}
+
+// FieldDelta is the change of one field.
+type FieldDelta struct {
+	// Field is the JSON name, e.g. "subscribers". Fields the client does
+	// not know yet are compared as well.
+	Field string
+
+	// Before and After are the decoded JSON values; nil when the field was
+	// missing.
+	Before, After any
+
+	// Delta is After - Before for numbers, and 0 otherwise.
+	Delta float64
+}
+
+// Compare lists the differences from a to b. Prospects are matched by
+// channel ID, or by channel URL when they have none. Fields named in
+// ignore are not compared.
+//
+// Times, like last_video and created_at, only count as changed when they
+// fall on a different UTC date: the API sends some of them as relative
+// strings such as "3 days ago", which resolve to a slightly different time
+// on every fetch.
+func Compare(a, b *Snapshot, ignore ...string) *Diff {
+	d := &Diff{
+		SearchID: b.SearchID,
+		From:     a.Taken,
+		To:       b.Taken,
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, f := range ignore {
+		skip[f] = true
+	}
+
+	before := make(map[string]int)
+	for i := range a.Prospects {
+		for _, k := range a.Prospects[i].Identities() {
+			if _, ok := before[k]; !ok {
+				before[k] = i
+			}
+		}
+	}
+
+	matched := make([]bool, len(a.Prospects))
+	for i := range b.Prospects {
+		p := &b.Prospects[i]
+
+		j, ok := match(before, matched, p)
+		if !ok {
+			d.Added = append(d.Added, *p)
+			continue
+		}
+		matched[j] = true
+
+		fields := deltas(&a.Prospects[j], p, skip)
+		if len(fields) > 0 {
+			d.Changed = append(d.Changed, Change{
+				Before: a.Prospects[j],
+				After:  *p,
+				Fields: fields,
+			})
+		}
+	}
+
+	for i, m := range matched {
+		if !m {
+			d.Removed = append(d.Removed, a.Prospects[i])
+		}
+	}
+
+	return d
+}
+
+// match finds the unmatched prospect of the earlier snapshot that p is.
+func match(before map[string]int, matched []bool, p *prospety.Prospect) (int, bool) {
+	for _, k := range p.Identities() {
+		if j, ok := before[k]; ok && !matched[j] {
+			return j, true
+		}
+	}
+
+	return 0, false
+}
+
+func deltas(a, b *prospety.Prospect, skip map[string]bool) []FieldDelta {
+	fa := fieldsOf(a)
+	fb := fieldsOf(b)
+
+	names := make(map[string]bool, len(fa))
+	for k := range fa {
+		names[k] = true
+	}
+	for k := range fb {
+		names[k] = true
+	}
+
+	var res []FieldDelta
+	for name := range names {
+		if skip[name] || bytes.Equal(fa[name], fb[name]) {
+			continue
+		}
+
+		before, after := decode(fa[name]), decode(fb[name])
+		if jsonEqual(before, after) {
+			continue
+		}
+
+		if timeFields()[name] && sameDay(before, after) {
+			continue
+		}
+
+		fd := FieldDelta{
+			Field:  name,
+			Before: before,
+			After:  after,
+		}
+
+		x, xok := before.(float64)
+		y, yok := after.(float64)
+		if xok && yok {
+			fd.Delta = y - x
+		}
+
+		res = append(res, fd)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Field < res[j].Field
+	})
+
+	return res
+}
+
+// timeFields are the JSON names of the prospety.Time fields of
+// prospety.Prospect.
+var timeFields = sync.OnceValue(func() map[string]bool {
+	res := make(map[string]bool)
+	addTimeFields(res, reflect.TypeOf(prospety.Prospect{}))
+	return res
+})
+
+func addTimeFields(res map[string]bool, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addTimeFields(res, f.Type)
+			continue
+		}
+
+		if f.Type == reflect.TypeOf(prospety.Time{}) && name != "" && name != "-" {
+			res[name] = true
+		}
+	}
+}
+
+// sameDay reports whether the encoded times a and b are on the same UTC
+// date.
+func sameDay(a, b any) bool {
+	sa, aok := a.(string)
+	sb, bok := b.(string)
+	if !aok || !bok {
+		return false
+	}
+
+	ta, err := time.Parse(time.RFC3339Nano, sa)
+	if err != nil {
+		return false
+	}
+
+	tb, err := time.Parse(time.RFC3339Nano, sb)
+	if err != nil {
+		return false
+	}
+
+	ya, ma, da := ta.UTC().Date()
+	yb, mb, db := tb.UTC().Date()
+	return ya == yb && ma == mb && da == db
+}
+
+// fieldsOf returns the encoded fields of p, including those it only keeps
+// in Extra.
+func fieldsOf(p *prospety.Prospect) map[string]json.RawMessage {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+
+	var res map[string]json.RawMessage
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil
+	}
+
+	return res
+}
+
+func decode(raw json.RawMessage) any {
+	if raw == nil {
+		return nil
+	}
+
+	var v any
+	err := json.Unmarshal(raw, &v)
+	if err != nil {
+		return nil
+	}
+
+	return v
+}
+
+// jsonEqual compares decoded JSON values, treating a missing field, null
+// and an empty list alike.
+func jsonEqual(a, b any) bool {
+	ea, _ := json.Marshal(normalizeEmpty(a))
+	eb, _ := json.Marshal(normalizeEmpty(b))
+
+	return bytes.Equal(ea, eb)
+}
+
+func normalizeEmpty(v any) any {
+	if l, ok := v.([]any); ok && len(l) == 0 {
+		return nil
+	}
+
+	return v
+}
@@ -0,0 +1,121 @@
+package snapshot_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+	"github.com/bjornpagen/prospety-go/snapshot"
+)
+
+func prospect(url string, subscribers int64) prospety.Prospect {
+	p := prospety.Prospect{
+		ChannelID:   prospety.ChannelYouTube,
+		Subscribers: subscribers,
+	}
+	p.URL = url
+
+	return p
+}
+
+func at(s string) prospety.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+
+	return prospety.Time{Time: t}
+}
+
+func TestCompare(t *testing.T) {
+	const id = "UC0123456789abcdefghijkl"
+
+	a := &snapshot.Snapshot{SearchID: 1}
+	b := &snapshot.Snapshot{SearchID: 1}
+
+	// Matched by channel ID although the URL changed.
+	kept := prospect("https://www.youtube.com/channel/"+id, 100)
+	kept.YouTubeChannelID = id
+	kept.Engagement = 0.1
+	kept.CreatedAt = at("2024-01-01T01:00:00Z")
+	kept.LastVideo = at("2024-06-01T23:00:00Z")
+	a.Prospects = append(a.Prospects, kept)
+
+	kept.URL = "https://www.youtube.com/@kept"
+	kept.Subscribers = 150
+	kept.Engagement = 0.2
+	kept.CreatedAt = at("2024-01-01T22:00:00Z")
+	kept.LastVideo = at("2024-06-02T01:00:00Z")
+	b.Prospects = append(b.Prospects, kept)
+
+	// Matched by URL, with a field the client does not know.
+	extra := prospect("https://www.youtube.com/@extra", 10)
+	extra.Extra = map[string]json.RawMessage{"tier": json.RawMessage(`"gold"`)}
+	a.Prospects = append(a.Prospects, extra)
+
+	extra.URL = "youtube.com/@Extra/"
+	extra.Extra = map[string]json.RawMessage{"tier": json.RawMessage(`"platinum"`)}
+	b.Prospects = append(b.Prospects, extra)
+
+	// Unchanged.
+	same := prospect("https://www.youtube.com/@same", 5)
+	a.Prospects = append(a.Prospects, same)
+	b.Prospects = append(b.Prospects, same)
+
+	// The same platform is no reason to match.
+	a.Prospects = append(a.Prospects, prospect("https://www.youtube.com/@gone", 1))
+	b.Prospects = append(b.Prospects, prospect("https://www.youtube.com/@new", 1))
+
+	d := snapshot.Compare(a, b, "engagement")
+
+	if len(d.Added) != 1 || d.Added[0].URL != "https://www.youtube.com/@new" {
+		t.Errorf("added = %+v, want @new", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].URL != "https://www.youtube.com/@gone" {
+		t.Errorf("removed = %+v, want @gone", d.Removed)
+	}
+	if len(d.Changed) != 2 {
+		t.Fatalf("got %d changes, want 2", len(d.Changed))
+	}
+
+	c := d.Changed[0]
+	var fields []string
+	for _, f := range c.Fields {
+		fields = append(fields, f.Field)
+	}
+	// Times count as changed on another UTC date, however close; the
+	// ignored engagement does not count at all.
+	if len(fields) != 3 || fields[0] != "last_video" || fields[1] != "subscribers" || fields[2] != "url" {
+		t.Errorf("changed fields = %q, want last_video, subscribers and url", fields)
+	}
+
+	subs, ok := c.Delta("subscribers")
+	if !ok || subs.Before != 100.0 || subs.After != 150.0 || subs.Delta != 50 {
+		t.Errorf("subscribers delta = %+v, want 100 -> 150 (+50)", subs)
+	}
+
+	lv, ok := c.Delta("last_video")
+	if !ok || lv.Delta != 0 {
+		t.Errorf("last_video delta = %+v, want a change without a delta", lv)
+	}
+
+	tier, ok := d.Changed[1].Delta("tier")
+	if !ok || tier.Before != "gold" || tier.After != "platinum" || tier.Delta != 0 {
+		t.Errorf("tier delta = %+v, want gold -> platinum", tier)
+	}
+	if _, ok := d.Changed[1].Delta("url"); !ok {
+		t.Errorf("url of the matched prospect did not change")
+	}
+}
+
+func TestCompareEmpty(t *testing.T) {
+	s := &snapshot.Snapshot{
+		SearchID:  1,
+		Prospects: []prospety.Prospect{prospect("https://www.youtube.com/@a", 1)},
+	}
+
+	if d := snapshot.Compare(s, s); !d.Empty() {
+		t.Errorf("comparing a snapshot with itself = %+v, want no changes", d)
+	}
+}
@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bjornpagen/prospety-go/internal/safefile"
+)
+
+// _fileTime names snapshot files so they sort by time.
+const _fileTime = "20060102T150405.000000000Z"
+
+// Dir stores every snapshot as a JSON file, in one directory per search.
+type Dir struct {
+	dir string
+}
+
+var _ Store = (*Dir)(nil)
+
+func NewDir(dir string) (*Dir, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	return &Dir{
+		dir: dir,
+	}, nil
+}
+
+func (d *Dir) searchDir(searchID int) string {
+	return filepath.Join(d.dir, strconv.Itoa(searchID))
+}
+
+func (d *Dir) path(searchID int, taken time.Time) string {
+	return filepath.Join(d.searchDir(searchID), taken.UTC().Format(_fileTime)+".json")
+}
+
+func (d *Dir) Save(s *Snapshot) error {
+	if s.Taken.IsZero() {
+		return fmt.Errorf("snapshot of search %d has no time", s.SearchID)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	dir := d.searchDir(s.SearchID)
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	err = safefile.Write(d.path(s.SearchID, s.Taken), data)
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Dir) List(searchID int) ([]time.Time, error) {
+	entries, err := os.ReadDir(d.searchDir(searchID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var res []time.Time
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+
+		t, err := time.Parse(_fileTime, name)
+		if err != nil {
+			continue
+		}
+		res = append(res, t)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Before(res[j])
+	})
+
+	return res, nil
+}
+
+func (d *Dir) Load(searchID int, taken time.Time) (*Snapshot, error) {
+	data, err := os.ReadFile(d.path(searchID, taken))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("search %d at %s: %w", searchID, taken.Format(time.RFC3339), ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	s := &Snapshot{}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,129 @@
+// Package snapshot records the prospects of a search over time and reports
+// what changed between two recordings.
+//
+//	store, err := snapshot.NewDir("snapshots")
+//	...
+//	_, err = snapshot.Take(c, store, searchID)
+//	...
+//	diff, err := snapshot.DiffLatest(store, searchID)
+//	for _, p := range diff.Added {
+//		fmt.Println("new lead:", p.Name)
+//	}
+//
+// Dir keeps snapshots in a directory. Other backends, such as a database,
+// can implement Store.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+// ErrNotFound is returned for snapshots that do not exist.
+var ErrNotFound = errors.New("snapshot not found")
+
+// Snapshot is the prospects of a search at one point in time.
+type Snapshot struct {
+	SearchID  int                 `json:"search_id"`
+	Taken     time.Time           `json:"taken"`
+	Prospects []prospety.Prospect `json:"prospects"`
+}
+
+// Store keeps snapshots by search and time.
+type Store interface {
+	Save(s *Snapshot) error
+
+	// List returns the times of the snapshots of a search, oldest first.
+	List(searchID int) ([]time.Time, error)
+
+	Load(searchID int, taken time.Time) (*Snapshot, error)
+}
+
+// Take fetches the prospects of a search and saves them.
+func Take(c *prospety.Client, store Store, searchID int) (*Snapshot, error) {
+	prospects, err := c.GetProspects(searchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take snapshot: %w", err)
+	}
+
+	s := &Snapshot{
+		SearchID:  searchID,
+		Taken:     time.Now().UTC(),
+		Prospects: prospects,
+	}
+
+	err = store.Save(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	return s, nil
+}
+
+// Latest returns the most recent snapshot of a search.
+func Latest(store Store, searchID int) (*Snapshot, error) {
+	times, err := store.List(searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(times) == 0 {
+		return nil, fmt.Errorf("search %d: %w", searchID, ErrNotFound)
+	}
+
+	return store.Load(searchID, times[len(times)-1])
+}
+
+// DiffLatest compares the two most recent snapshots of a search.
+func DiffLatest(store Store, searchID int, ignore ...string) (*Diff, error) {
+	times, err := store.List(searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(times) < 2 {
+		return nil, fmt.Errorf("search %d: need two snapshots, have %d: %w", searchID, len(times), ErrNotFound)
+	}
+
+	return diffTimes(store, searchID, times[len(times)-2], times[len(times)-1], ignore)
+}
+
+// DiffSince compares the last snapshot taken at or before since with the
+// most recent one, e.g. for what changed in the last week.
+func DiffSince(store Store, searchID int, since time.Time, ignore ...string) (*Diff, error) {
+	times, err := store.List(searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var from time.Time
+	for _, t := range times {
+		if t.After(since) {
+			break
+		}
+		from = t
+	}
+
+	if from.IsZero() {
+		return nil, fmt.Errorf("search %d: no snapshot before %s: %w", searchID, since.Format(time.RFC3339), ErrNotFound)
+	}
+
+	return diffTimes(store, searchID, from, times[len(times)-1], ignore)
+}
+
+func diffTimes(store Store, searchID int, from, to time.Time, ignore []string) (*Diff, error) {
+	a, err := store.Load(searchID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := store.Load(searchID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(a, b, ignore...), nil
+}
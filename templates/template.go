@@ -0,0 +1,228 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+const (
+	_keyExtends  = "extends"
+	_keyVars     = "vars"
+	_keyType     = "type"
+	_keyTitle    = "title"
+	_keyLimit    = "limit"
+	_keyCriteria = "criteria"
+)
+
+type Template struct {
+	Name  string
+	Type  string
+	Title string
+	Limit int
+
+	// Exactly one of these is set, depending on Type.
+	Standard *prospety.StandardSearch
+	Similar  *prospety.SimilarSearchCriteria
+}
+
+// Criteria returns the value to pass to GetPotentialProspects and
+// GetPotentialProspectsCount.
+func (t *Template) Criteria() any {
+	if t.Similar != nil {
+		return *t.Similar
+	}
+
+	return t.Standard.StandardSearchCriteria
+}
+
+// Search returns the value to pass to CreateSearch and UpdateSearch.
+func (t *Template) Search() (prospety.StandardSearch, error) {
+	if t.Standard == nil {
+		return prospety.StandardSearch{}, fmt.Errorf("template %q: %s templates cannot be saved as searches", t.Name, t.Type)
+	}
+
+	return *t.Standard, nil
+}
+
+// Spec returns the desired state of the search for Client.Apply, labelled
+// with the template name.
+func (t *Template) Spec() (prospety.SearchSpec, error) {
+	search, err := t.Search()
+	if err != nil {
+		return prospety.SearchSpec{}, err
+	}
+
+	return prospety.SearchSpec{
+		Label: t.Name,
+		Title: t.Title,
+		Limit: t.Limit,
+		Data:  search,
+	}, nil
+}
+
+func (t *Template) Create(c *prospety.Client) error {
+	search, err := t.Search()
+	if err != nil {
+		return err
+	}
+
+	if t.Title == "" {
+		return fmt.Errorf("template %q: title is required to create a search", t.Name)
+	}
+
+	if t.Limit <= 0 {
+		return fmt.Errorf("template %q: limit is required to create a search", t.Name)
+	}
+
+	return c.CreateSearch(t.Title, t.Limit, search)
+}
+
+func build(name string, raw map[string]any) (*Template, error) {
+	t := &Template{
+		Name: name,
+		Type: prospety.SearchTypeStandard,
+	}
+
+	for k, v := range raw {
+		var ok bool
+		switch k {
+		case _keyVars, _keyCriteria:
+			ok = true
+		case _keyType:
+			t.Type, ok = v.(string)
+		case _keyTitle:
+			t.Title, ok = v.(string)
+		case _keyLimit:
+			t.Limit, ok = toInt(v)
+		default:
+			return nil, fmt.Errorf("unknown key %q", k)
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("invalid value for %q: %v", k, v)
+		}
+	}
+
+	criteria, ok := raw[_keyCriteria]
+	if !ok {
+		criteria = map[string]any{}
+	}
+
+	var err error
+	switch t.Type {
+	case prospety.SearchTypeStandard:
+		t.Standard = &prospety.StandardSearch{}
+		err = decode(criteria, t.Standard)
+	case prospety.SearchTypeSimilar:
+		t.Similar = &prospety.SimilarSearchCriteria{}
+		err = decode(criteria, t.Similar)
+	default:
+		return nil, fmt.Errorf("unsupported search type %q", t.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid criteria: %w", err)
+	}
+
+	err = t.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// decode round-trips v through JSON so the json tags of the prospety types
+// decide the field names. Unknown fields are rejected to catch typos.
+func decode(v any, dst any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(dst)
+}
+
+var _varPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// substitute replaces ${name} references in every string of v. A string
+// that consists of a single reference takes the variable's value as is, so
+// numbers and lists keep their type.
+func substitute(v any, vars map[string]any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if m := _varPattern.FindStringSubmatch(v); m != nil && m[0] == v {
+			val, ok := vars[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("undefined variable %q", m[1])
+			}
+			return val, nil
+		}
+
+		var missing string
+		res := _varPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			name := _varPattern.FindStringSubmatch(ref)[1]
+			val, ok := vars[name]
+			if !ok {
+				missing = name
+				return ref
+			}
+			return fmt.Sprint(val)
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("undefined variable %q", missing)
+		}
+
+		return res, nil
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, val := range v {
+			// Variable declarations are substituted into the template, not
+			// into each other.
+			if k == _keyVars {
+				res[k] = val
+				continue
+			}
+
+			sub, err := substitute(val, vars)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = sub
+		}
+		return res, nil
+	case []any:
+		res := make([]any, len(v))
+		for i, val := range v {
+			sub, err := substitute(val, vars)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = sub
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+func toInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), v == float64(int(v))
+	case string:
+		i, err := strconv.Atoi(v)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
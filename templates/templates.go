@@ -0,0 +1,254 @@
+// Package templates loads saved search definitions from YAML or JSON files.
+//
+// A file looks like this:
+//
+//	vars:
+//	  country: US
+//	templates:
+//	  base-gaming-us:
+//	    type: standard
+//	    criteria:
+//	      category: ["20"]
+//	      country: ["${country}"]
+//	  gaming-us-big:
+//	    extends: base-gaming-us
+//	    title: Gaming ${country} (big)
+//	    limit: 500
+//	    criteria:
+//	      subscribers_range: [100000, 10000000]
+//
+// Criteria keys are the JSON field names of the prospety criteria types.
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type file struct {
+	Vars      map[string]any            `yaml:"vars"`
+	Templates map[string]map[string]any `yaml:"templates"`
+}
+
+type definition struct {
+	source string
+	vars   map[string]any
+	raw    map[string]any
+}
+
+type Set struct {
+	defs map[string]*definition
+}
+
+func NewSet() *Set {
+	return &Set{
+		defs: make(map[string]*definition),
+	}
+}
+
+// Load reads every file into a single set, so templates may extend
+// templates defined in other files.
+func Load(paths ...string) (*Set, error) {
+	s := NewSet()
+	for _, p := range paths {
+		err := s.AddFile(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// LoadDir loads every .yaml, .yml and .json file in dir.
+func LoadDir(dir string) (*Set, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	return Load(paths...)
+}
+
+func (s *Set) AddFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	err = s.Add(path, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Add parses data as YAML (which includes JSON) and adds its templates
+// to the set. source is only used in error messages.
+func (s *Set) Add(source string, data []byte) error {
+	f := &file{}
+	err := yaml.Unmarshal(data, f)
+	if err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	for name, raw := range f.Templates {
+		if prev, ok := s.defs[name]; ok {
+			return fmt.Errorf("template %q already defined in %s", name, prev.source)
+		}
+
+		s.defs[name] = &definition{
+			source: source,
+			vars:   f.Vars,
+			raw:    raw,
+		}
+	}
+
+	return nil
+}
+
+func (s *Set) Names() []string {
+	names := make([]string, 0, len(s.defs))
+	for name := range s.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Resolve flattens the inheritance chain of the named template, substitutes
+// variables and validates the result. vars override any variables declared
+// in the files.
+func (s *Set) Resolve(name string, vars map[string]any) (*Template, error) {
+	raw, fileVars, err := s.flatten(name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]any)
+	for k, v := range fileVars {
+		all[k] = v
+	}
+	if tv, ok := raw[_keyVars].(map[string]any); ok {
+		for k, v := range tv {
+			all[k] = v
+		}
+	}
+	for k, v := range vars {
+		all[k] = v
+	}
+
+	expanded, err := substitute(raw, all)
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %w", name, err)
+	}
+
+	t, err := build(name, expanded.(map[string]any))
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// ResolveAll resolves every template in the set. Templates that only serve
+// as a base for others still have to be valid on their own.
+func (s *Set) ResolveAll(vars map[string]any) ([]*Template, error) {
+	var res []*Template
+	for _, name := range s.Names() {
+		t, err := s.Resolve(name, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, t)
+	}
+
+	return res, nil
+}
+
+func (s *Set) flatten(name string, seen []string) (map[string]any, map[string]any, error) {
+	for _, n := range seen {
+		if n == name {
+			return nil, nil, fmt.Errorf("template inheritance cycle: %s -> %s", strings.Join(seen, " -> "), name)
+		}
+	}
+	seen = append(seen, name)
+
+	def, ok := s.defs[name]
+	if !ok {
+		if len(seen) > 1 {
+			return nil, nil, fmt.Errorf("template %q extends unknown template %q", seen[len(seen)-2], name)
+		}
+		return nil, nil, fmt.Errorf("unknown template %q", name)
+	}
+
+	parent, ok := def.raw[_keyExtends]
+	if !ok {
+		return def.raw, def.vars, nil
+	}
+
+	parentName, ok := parent.(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("template %q: extends must be a template name", name)
+	}
+
+	base, baseVars, err := s.flatten(parentName, seen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw := merge(base, def.raw)
+	delete(raw, _keyExtends)
+
+	vars := make(map[string]any)
+	for k, v := range baseVars {
+		vars[k] = v
+	}
+	for k, v := range def.vars {
+		vars[k] = v
+	}
+
+	return raw, vars, nil
+}
+
+// merge returns base overlaid with override. Nested maps are merged
+// recursively; everything else, including lists, is replaced.
+func merge(base, override map[string]any) map[string]any {
+	res := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		res[k] = v
+	}
+
+	for k, v := range override {
+		bm, bok := res[k].(map[string]any)
+		om, ook := v.(map[string]any)
+		if bok && ook {
+			res[k] = merge(bm, om)
+			continue
+		}
+
+		res[k] = v
+	}
+
+	return res
+}
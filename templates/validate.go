@@ -0,0 +1,113 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	prospety "github.com/bjornpagen/prospety-go"
+)
+
+var _youTubeCategories = map[prospety.YouTubeCategory]bool{
+	prospety.YouTubeCategoryAutosVehicles: true,
+	prospety.YouTubeCategoryComedy:        true,
+	prospety.YouTubeCategoryEducation:     true,
+	prospety.YouTubeCategoryEntertainment: true,
+	prospety.YouTubeCategoryFilmAnimation: true,
+	prospety.YouTubeCategoryGaming:        true,
+	prospety.YouTubeCategoryHowtoStyle:    true,
+	prospety.YouTubeCategoryMusic:         true,
+	prospety.YouTubeCategoryNewsPolitics:  true,
+	prospety.YouTubeCategoryNonprofits:    true,
+	prospety.YouTubeCategoryPeopleBlogs:   true,
+	prospety.YouTubeCategoryPetsAnimals:   true,
+	prospety.YouTubeCategoryScienceTech:   true,
+	prospety.YouTubeCategorySports:        true,
+	prospety.YouTubeCategoryTravelEvents:  true,
+	prospety.YouTubeCategoryShows:         true,
+	prospety.YouTubeCategoryTrailers:      true,
+}
+
+func (t *Template) Validate() error {
+	var errs []error
+
+	if t.Limit < 0 {
+		errs = append(errs, fmt.Errorf("limit must not be negative"))
+	}
+
+	if t.Standard != nil {
+		errs = append(errs, validateStandard(&t.Standard.StandardSearchCriteria)...)
+	}
+
+	if t.Similar != nil {
+		errs = append(errs, validateSimilar(t.Similar)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateStandard(c *prospety.StandardSearchCriteria) []error {
+	var errs []error
+
+	for _, cat := range c.Category {
+		id, err := strconv.Atoi(cat)
+		if err != nil || !_youTubeCategories[id] {
+			errs = append(errs, fmt.Errorf("category: unknown YouTube category %q", cat))
+		}
+	}
+
+	errs = appendRange(errs, "subscribers_range", c.SubscribersRange)
+	errs = appendRange(errs, "total_views_range", c.TotalViewsRange)
+	errs = appendRange(errs, "average_views_per_video_range", c.AverageViewsPerVideoRange)
+	errs = appendRange(errs, "total_videos_range", c.TotalVideosRange)
+	errs = appendRange(errs, "latest_video_range", c.LatestVideoRange)
+	errs = appendRange(errs, "created_range", c.CreatedRange)
+
+	return errs
+}
+
+func validateSimilar(c *prospety.SimilarSearchCriteria) []error {
+	var errs []error
+
+	if len(c.References) == 0 {
+		errs = append(errs, fmt.Errorf("references: at least one reference channel is required"))
+	}
+
+	for _, ref := range c.References {
+		u, err := prospety.ParseChannelURL(ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("references: %w", err))
+			continue
+		}
+
+		if u.Channel != prospety.ChannelYouTube {
+			errs = append(errs, fmt.Errorf("references: %q is not a YouTube channel", ref))
+		}
+	}
+
+	errs = appendRange(errs, "subscribers_difference_range", c.SubscribersDifferenceRange)
+	errs = appendRange(errs, "total_views_difference_range", c.TotalViewsDifferenceRange)
+	errs = appendRange(errs, "average_views_per_video_difference_range", c.AverageViewsPerVideoDifferenceRange)
+	errs = appendRange(errs, "total_videos_difference_range", c.TotalVideosDifferenceRange)
+	errs = appendRange(errs, "latest_video_difference_range", c.LatestVideoDifferenceRange)
+	errs = appendRange(errs, "created_difference_range", c.CreatedDifferenceRange)
+
+	return errs
+}
+
+// appendRange checks that r is either unset or a [min, max] pair.
+func appendRange[T int | int64](errs []error, name string, r []T) []error {
+	if len(r) == 0 {
+		return errs
+	}
+
+	if len(r) != 2 {
+		return append(errs, fmt.Errorf("%s: expected [min, max], got %d values", name, len(r)))
+	}
+
+	if r[0] > r[1] {
+		return append(errs, fmt.Errorf("%s: min %d is greater than max %d", name, r[0], r[1]))
+	}
+
+	return errs
+}
@@ -0,0 +1,119 @@
+package prospety
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time is a timestamp in any of the formats the API emits: RFC 3339,
+// "2006-01-02 15:04:05", plain dates, unix timestamps and relative strings
+// such as "3 days ago". Relative strings are resolved when decoded. Time
+// always encodes as RFC 3339, or null when zero.
+type Time struct {
+	time.Time
+}
+
+var _timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+var _relativePattern = regexp.MustCompile(`^(\d+|a|an|one) (second|minute|hour|day|week|month|year)s? ago$`)
+
+// ParseTime parses s relative to now.
+func ParseTime(s string, now time.Time) (Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Time{}, nil
+	}
+
+	for _, layout := range _timeLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return Time{t}, nil
+		}
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Time{time.Unix(n, 0).UTC()}, nil
+	}
+
+	lower := strings.ToLower(s)
+	switch lower {
+	case "just now", "now", "today":
+		return Time{now}, nil
+	case "yesterday":
+		return Time{now.AddDate(0, 0, -1)}, nil
+	}
+
+	m := _relativePattern.FindStringSubmatch(lower)
+	if m == nil {
+		return Time{}, fmt.Errorf("unrecognized time %q", s)
+	}
+
+	n := 1
+	if v, err := strconv.Atoi(m[1]); err == nil {
+		n = v
+	}
+
+	var t time.Time
+	switch m[2] {
+	case "second":
+		t = now.Add(-time.Duration(n) * time.Second)
+	case "minute":
+		t = now.Add(-time.Duration(n) * time.Minute)
+	case "hour":
+		t = now.Add(-time.Duration(n) * time.Hour)
+	case "day":
+		t = now.AddDate(0, 0, -n)
+	case "week":
+		t = now.AddDate(0, 0, -7*n)
+	case "month":
+		t = now.AddDate(0, -n, 0)
+	case "year":
+		t = now.AddDate(-n, 0, 0)
+	}
+
+	return Time{t}, nil
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(t.Format(time.RFC3339Nano))
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return err
+		}
+	} else {
+		s = string(data)
+	}
+
+	parsed, err := ParseTime(s, time.Now())
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
@@ -1,7 +1,7 @@
 package prospety
 
 import (
-	"time"
+	"encoding/json"
 )
 
 type ChannelType = int
@@ -52,8 +52,8 @@ const (
 type QuickSearch struct {
 	ID        int              `json:"id"`
 	Status    SearchStatus     `json:"status"`
-	CreatedAt time.Time        `json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
+	CreatedAt Time             `json:"created_at"`
+	UpdatedAt Time             `json:"updated_at"`
 	Prospect  *ProspectPreview `json:"prospect"`
 }
 
@@ -127,10 +127,10 @@ type Search struct {
 	ChannelID          int            `json:"channel_id"`
 	ChannelTitle       string         `json:"channel_title"`
 	Limit              int            `json:"limit"`
-	CreatedAtFormatted string         `json:"created_at_formatted"`
-	UpdatedAtFormatted string         `json:"updated_at_formatted"`
-	CreatedAt          string         `json:"created_at"`
-	UpdatedAt          string         `json:"updated_at"`
+	CreatedAtFormatted string         `json:"created_at_formatted"` // for display only
+	UpdatedAtFormatted string         `json:"updated_at_formatted"` // for display only
+	CreatedAt          Time           `json:"created_at"`
+	UpdatedAt          Time           `json:"updated_at"`
 	Progress           SearchProgress `json:"progress"`
 	Searched           bool           `json:"searched"`
 	GatheringProspects bool           `json:"gathering_prospects"`
@@ -145,16 +145,37 @@ type SearchProgress struct {
 type Prospect struct {
 	ProspectPreview
 
-	Email    string   `json:"email"`
-	Phone    string   `json:"phone"`
-	Keywords []string `json:"keywords"`
-	//VideoKeywords []string `json:"video_keywords"`
-	Category    string   `json:"category"`
-	Country     string   `json:"country"`
-	Links       []string `json:"links"`
-	CreatedAt   string   `json:"created_at"`
-	Subscribers int64    `json:"subscribers"`
-	TotalViews  int64    `json:"total_views"`
-	TotalVideos int      `json:"total_videos"`
-	LastVideo   string   `json:"last_video"`
+	// ChannelID is the platform, as in Search.ChannelID.
+	ChannelID ChannelType `json:"channel_id"`
+
+	// YouTubeChannelID is the "UC…" ID of a YouTube channel.
+	YouTubeChannelID string `json:"youtube_channel_id"`
+
+	Description   string   `json:"description"`
+	Email         string   `json:"email"`
+	EmailStatus   string   `json:"email_status"`
+	Phone         string   `json:"phone"`
+	Keywords      []string `json:"keywords"`
+	VideoKeywords []string `json:"video_keywords"`
+	Category      string   `json:"category"`
+	Country       string   `json:"country"`
+	Links         []string `json:"links"`
+	CreatedAt     Time     `json:"created_at"`
+	Subscribers   int64    `json:"subscribers"`
+	TotalViews    int64    `json:"total_views"`
+	TotalVideos   int      `json:"total_videos"`
+	AverageViews  int64    `json:"average_views"`
+	Engagement    float64  `json:"engagement"`
+	Score         float64  `json:"score"`
+	LastVideo     Time     `json:"last_video"`
+
+	// Extra holds the fields the API returned that Prospect does not know
+	// about yet. They are written back when the Prospect is encoded.
+	Extra map[string]json.RawMessage `json:"-"`
 }
+
+const (
+	EmailStatusVerified   = "verified"
+	EmailStatusUnverified = "unverified"
+	EmailStatusInvalid    = "invalid"
+)
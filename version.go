@@ -0,0 +1,191 @@
+package prospety
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+const _defaultBaseURL = "https://app.prospety.com/api"
+
+// ErrNoSupportedAPIVersion is returned when none of the versions passed to
+// WithAPIVersionNegotiation is served by the API.
+var ErrNoSupportedAPIVersion = errors.New("no supported API version")
+
+// WithBaseURL sets the scheme, host and path prefix of every request, e.g.
+// http://localhost:8080/fake or https://proxy.internal/prospety/api.
+func WithBaseURL(u *url.URL) Option {
+	return func(option *options) error {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid base URL %q: scheme must be http or https", u)
+		}
+
+		if u.Host == "" {
+			return fmt.Errorf("invalid base URL %q: missing host", u)
+		}
+
+		if u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("invalid base URL %q: must not have a query or fragment", u)
+		}
+
+		base := *u
+		base.Path = strings.TrimSuffix(base.Path, "/")
+		base.RawPath = ""
+		option.baseURL = &base
+		return nil
+	}
+}
+
+// WithAPIVersion pins the API version. The version is appended to the base
+// URL path, e.g. /api/v2, and sent in the X-API-Version header.
+func WithAPIVersion(version string) Option {
+	return func(option *options) error {
+		if strings.Contains(version, "/") {
+			return fmt.Errorf("invalid API version %q", version)
+		}
+
+		option.version = &apiVersion{
+			version: version,
+		}
+		return nil
+	}
+}
+
+// WithAPIVersionNegotiation picks the first of versions, in order of
+// preference, that the API serves. The empty string stands for the
+// unversioned API. Negotiation happens on the first call.
+func WithAPIVersionNegotiation(versions ...string) Option {
+	return func(option *options) error {
+		if len(versions) == 0 {
+			return fmt.Errorf("no API versions to negotiate")
+		}
+
+		for _, v := range versions {
+			if strings.Contains(v, "/") {
+				return fmt.Errorf("invalid API version %q", v)
+			}
+		}
+
+		option.version = &apiVersion{
+			candidates: versions,
+		}
+		return nil
+	}
+}
+
+type apiVersion struct {
+	candidates []string
+
+	mu         sync.Mutex
+	negotiated bool
+	version    string
+	err        error
+}
+
+// APIVersion returns the pinned or negotiated API version, negotiating it
+// first if necessary. It is empty for the unversioned API. A negotiation
+// that failed for reasons other than ErrNoSupportedAPIVersion is retried on
+// the next call.
+func (c *Client) APIVersion() (string, error) {
+	v := c.options.version
+	if v == nil || len(v.candidates) == 0 {
+		return v.pinned(), nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.negotiated {
+		return v.version, v.err
+	}
+
+	version, err := c.negotiateAPIVersion(v.candidates)
+	if err != nil && !errors.Is(err, ErrNoSupportedAPIVersion) {
+		return "", err
+	}
+
+	v.negotiated = true
+	v.version, v.err = version, err
+
+	return version, err
+}
+
+func (v *apiVersion) pinned() string {
+	if v == nil {
+		return ""
+	}
+
+	return v.version
+}
+
+// negotiateAPIVersion probes a cheap listing for each candidate. A version
+// is rejected when the API answers 404 Not Found or 406 Not Acceptable;
+// any other error ends the negotiation.
+func (c *Client) negotiateAPIVersion(candidates []string) (string, error) {
+	for _, v := range candidates {
+		p := []string{"channels"}
+		u := c.versionedUrl(v, p) + "?" + pageParams(1, 0).encode()
+		req, err := http.NewRequestWithContext(c.context(), "GET", u, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create request: %w", err)
+		}
+		setVersionHeader(req, v)
+
+		_, err = c.do(req, p)
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusNotAcceptable) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to negotiate API version: %w", err)
+		}
+
+		return v, nil
+	}
+
+	return "", fmt.Errorf("%w: tried %q", ErrNoSupportedAPIVersion, candidates)
+}
+
+func (c *Client) versionedUrl(version string, p []string) string {
+	u := *c.options.baseURL
+
+	segs := append([]string{u.Path, version}, p...)
+	u.Path = path.Join(segs...)
+
+	return u.String()
+}
+
+func setVersionHeader(req *http.Request, version string) {
+	if version != "" {
+		req.Header.Set("X-API-Version", version)
+	}
+}
+
+// newRequest creates a request for the versioned URL of p.
+func (c *Client) newRequest(method string, p []string, q params, body []byte) (*http.Request, error) {
+	version, err := c.APIVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.buildUrlWithParameters(p, q)
+
+	var req *http.Request
+	if body != nil {
+		req, err = http.NewRequestWithContext(c.context(), method, u, bytes.NewReader(body))
+	} else {
+		req, err = http.NewRequestWithContext(c.context(), method, u, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	setVersionHeader(req, version)
+
+	return req, nil
+}